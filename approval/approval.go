@@ -0,0 +1,261 @@
+// Package approval remembers the user's answers to "ask" decisions, so that
+// a command approved once is not asked about again for a while.
+//
+// A hook never sees the user's answer directly. An asked call that the user
+// approves runs, and the host then sends PostToolUse for it; a rejected one
+// does not. Memory therefore records each ask it lets through and turns it
+// into an approval when the matching PostToolUse arrives. Matching later
+// calls, by fingerprint, are allowed without asking until the approval
+// expires.
+package approval
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/callkey"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/projectdir"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Scope selects who shares an approval.
+type Scope string
+
+const (
+	// ScopeSession keeps approvals within the session they were given in.
+	ScopeSession Scope = "session"
+	// ScopeProject shares approvals between the sessions of a project,
+	// found from the session's working directory as config.ProjectRoot
+	// does, so that sessions started in subdirectories share them too.
+	ScopeProject Scope = "project"
+)
+
+const (
+	// DefaultPeriod is how long an approval lasts.
+	DefaultPeriod = time.Hour
+	// PendingTTL bounds how long an ask waits for its PostToolUse, which
+	// never comes when the user rejects the call.
+	PendingTTL = time.Hour
+
+	pendingPrefix = "approval/pending/"
+	grantPrefix   = "approval/grant/"
+	// projectSession is the state session holding project approvals.
+	projectSession = "approvals"
+)
+
+// Grant is a remembered approval.
+type Grant struct {
+	ToolName    types.ToolName `json:"tool_name"`
+	Fingerprint string         `json:"fingerprint"`
+	// Summary is the approved command, for Bash, to make stored grants
+	// readable.
+	Summary    string    `json:"summary,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	ApprovedAt time.Time `json:"approved_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Memory remembers approvals of asked calls. Wrap the handlers whose asks
+// it should remember and register Memory itself for PostToolUse, where it
+// collects the evidence and makes no decision:
+//
+//	memory := approval.New().WithScope(approval.ScopeProject)
+//	router := handler.NewRouter().
+//	    On(types.EventPreToolUse, memory.Wrap(policyHandler)).
+//	    On(types.EventPostToolUse, memory)
+//
+// Memory is best effort: state errors leave the wrapped handler's decision
+// unchanged.
+type Memory struct {
+	scope       Scope
+	period      time.Duration
+	fingerprint FingerprintFunc
+	now         func() time.Time
+}
+
+func New() *Memory {
+	return &Memory{
+		scope:       ScopeSession,
+		period:      DefaultPeriod,
+		fingerprint: Fingerprint,
+		now:         time.Now,
+	}
+}
+
+func (m *Memory) WithScope(scope Scope) *Memory {
+	m.scope = scope
+	return m
+}
+
+// WithPeriod sets how long an approval lasts. Approvals are kept in the
+// session state, so a period longer than the state store's TTL only holds
+// while the project or session keeps being written to.
+func (m *Memory) WithPeriod(period time.Duration) *Memory {
+	m.period = period
+	return m
+}
+
+func (m *Memory) WithFingerprint(fn FingerprintFunc) *Memory {
+	m.fingerprint = fn
+	return m
+}
+
+// Wrap returns h with its asks remembered. The result keeps h's name, so it
+// can still be turned off by name.
+func (m *Memory) Wrap(h handler.Handler) handler.Handler {
+	return &remembering{memory: m, inner: h}
+}
+
+type remembering struct {
+	memory *Memory
+	inner  handler.Handler
+}
+
+func (r *remembering) HandlerName() string {
+	return handler.Name(r.inner)
+}
+
+func (r *remembering) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	output, err := r.inner.HandleEvent(input, eventName)
+	in, ok := input.(types.PreToolUseInput)
+	if !ok || err != nil {
+		return output, err
+	}
+	if out, ok := output.(types.PreToolUseOutput); ok && out.Decision() == types.PermissionAsk {
+		return r.memory.ask(in, out), nil
+	}
+	return output, nil
+}
+
+// ask returns an allow if the call was approved before and records the
+// ask otherwise.
+func (m *Memory) ask(input types.PreToolUseInput, output types.PreToolUseOutput) types.HookOutput {
+	logger := input.Logger()
+	fingerprint := m.fingerprint(input.ToolName, input.ToolInput)
+	grant, found, err := m.Lookup(input, fingerprint)
+	if err != nil {
+		logger.Warn("approval lookup failed", "error", err)
+		return output
+	}
+	if found {
+		logger.Debug("approval remembered", "fingerprint", fingerprint, "expires_at", grant.ExpiresAt)
+		return types.Allow(fmt.Sprintf("approved earlier in this %s (until %s)",
+			m.scope, grant.ExpiresAt.Local().Format(time.Kitchen)))
+	}
+
+	pending := Grant{
+		ToolName:    input.ToolName,
+		Fingerprint: fingerprint,
+		Summary:     summary(input.ToolName, input.ToolInput),
+		Reason:      output.Reason(),
+	}
+	key := pendingPrefix + callkey.Key(string(input.ToolName), input.ToolUseID, input.ToolInput)
+	if err := state.Update(input.Context(), func(tx *state.Tx) error {
+		return tx.SetWithTTL(key, pending, PendingTTL)
+	}); err != nil {
+		logger.Warn("approval not recorded", "error", err)
+	}
+	return output
+}
+
+func (m *Memory) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	if in, ok := input.(types.PostToolUseInput); ok {
+		return m.HandlePostToolUse(in)
+	}
+	return types.Success(), nil
+}
+
+// HandlePostToolUse turns the ask recorded for the call into an approval.
+func (m *Memory) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
+	m.approve(input)
+	return types.PostToolUseOutput{}, nil
+}
+
+func (m *Memory) approve(input types.PostToolUseInput) {
+	key := pendingPrefix + callkey.Key(string(input.ToolName), input.ToolUseID, input.ToolInput)
+	var grant Grant
+	var found bool
+	_ = state.Update(input.Context(), func(tx *state.Tx) error {
+		var err error
+		found, err = tx.Get(key, &grant)
+		tx.Delete(key)
+		return err
+	})
+	if !found {
+		return
+	}
+	grant.ApprovedAt = m.now()
+	grant.ExpiresAt = grant.ApprovedAt.Add(m.period)
+	session := m.session(input)
+	if session == nil {
+		return
+	}
+	if err := session.SetWithTTL(grantPrefix+grant.Fingerprint, grant, m.period); err != nil {
+		input.Logger().Warn("approval not remembered", "error", err)
+		return
+	}
+	input.Logger().Info("approval remembered", "tool_name", grant.ToolName, "fingerprint", grant.Fingerprint, "expires_at", grant.ExpiresAt)
+}
+
+// Lookup returns the unexpired approval with the given fingerprint in the
+// scope of input.
+func (m *Memory) Lookup(input types.HookInput, fingerprint string) (Grant, bool, error) {
+	session := m.session(input)
+	if session == nil {
+		return Grant{}, false, state.ErrNoSession
+	}
+	return state.Lookup[Grant](session, grantPrefix+fingerprint)
+}
+
+// Grants returns the unexpired approvals in the scope of input.
+func (m *Memory) Grants(input types.HookInput) ([]Grant, error) {
+	session := m.session(input)
+	if session == nil {
+		return nil, state.ErrNoSession
+	}
+	var grants []Grant
+	err := session.View(func(tx *state.Tx) error {
+		for _, key := range tx.Keys(grantPrefix) {
+			grant, _, err := state.Lookup[Grant](tx, key)
+			if err != nil {
+				return err
+			}
+			grants = append(grants, grant)
+		}
+		return nil
+	})
+	return grants, err
+}
+
+// Forget removes every approval in the scope of input.
+func (m *Memory) Forget(input types.HookInput) error {
+	session := m.session(input)
+	if session == nil {
+		return state.ErrNoSession
+	}
+	return session.Update(func(tx *state.Tx) error {
+		for _, key := range tx.Keys(grantPrefix) {
+			tx.Delete(key)
+		}
+		return nil
+	})
+}
+
+// session returns the state session approvals are kept in.
+func (m *Memory) session(input types.HookInput) *state.Session {
+	session := state.FromContext(input.Context())
+	if session == nil || m.scope != ScopeProject {
+		return session
+	}
+	return session.Store().Session(projectdir.Root(session.CWD()), projectSession).WithContext(input.Context())
+}
+
+func summary(toolName types.ToolName, toolInput map[string]interface{}) string {
+	if toolName != types.ToolBash {
+		return ""
+	}
+	command, _ := toolInput["command"].(string)
+	return NormalizeCommand(command)
+}
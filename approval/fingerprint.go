@@ -0,0 +1,80 @@
+package approval
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/callkey"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/shell"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// FingerprintFunc identifies the calls an approval covers. Calls with the
+// same fingerprint count as the same call.
+type FingerprintFunc func(toolName types.ToolName, toolInput map[string]interface{}) string
+
+// Fingerprint is the default FingerprintFunc. Bash calls are identified by
+// their normalized command alone, so the description the model attaches or
+// differences in spacing and line continuations do not matter. Other tools
+// are identified by their whole input.
+func Fingerprint(toolName types.ToolName, toolInput map[string]interface{}) string {
+	if toolName == types.ToolBash {
+		command, _ := toolInput["command"].(string)
+		return callkey.Fingerprint(string(toolName), map[string]interface{}{
+			"command": NormalizeCommand(command),
+		})
+	}
+	return callkey.Fingerprint(string(toolName), toolInput)
+}
+
+// NormalizeCommand rewrites a Bash command line with single spaces between
+// words, keeping each word as written. Commands the parser rejects, and
+// those with compound commands, are only trimmed.
+func NormalizeCommand(command string) string {
+	command = strings.TrimSpace(command)
+	script, err := shell.Parse(command)
+	if err != nil {
+		return command
+	}
+	var parts []string
+	for _, pipeline := range script.Pipelines {
+		if pipeline.Negated {
+			parts = append(parts, "!")
+		}
+		for i, cmd := range pipeline.Commands {
+			if len(cmd.Body) > 0 || cmd.Subshell || cmd.Function {
+				return command
+			}
+			if i > 0 {
+				parts = append(parts, "|")
+			}
+			for _, word := range cmd.Assignments {
+				parts = append(parts, word.Raw)
+			}
+			for _, word := range cmd.Args {
+				parts = append(parts, word.Raw)
+			}
+			for _, r := range cmd.Redirects {
+				parts = append(parts, redirect(r))
+			}
+		}
+		if pipeline.Op != "" {
+			parts = append(parts, pipeline.Op)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func redirect(r *shell.Redirect) string {
+	s := r.Op
+	if r.FD >= 0 {
+		s = strconv.Itoa(r.FD) + s
+	}
+	if r.Target != nil {
+		s += r.Target.Raw
+	}
+	if r.Heredoc != "" {
+		s += "\n" + r.Heredoc
+	}
+	return s
+}
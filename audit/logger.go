@@ -0,0 +1,264 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/filelock"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/secrets"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// FileEnv names the audit file used by FromEnv.
+const FileEnv = "CLAUDE_HOOKS_AUDIT_FILE"
+
+const (
+	DefaultMaxSize    = 10 << 20
+	DefaultMaxBackups = 5
+)
+
+// Logger is a handler.Observer that appends a Record for every invocation
+// to a JSON Lines file. Records are buffered and written by Flush, which
+// runs automatically before types.OutputAndExit or handler.Execute exit the
+// process.
+//
+// When the file would grow beyond the maximum size it is rotated to
+// path.1, path.1 to path.2 and so on, keeping at most MaxBackups old files.
+// Writes and rotation happen under a file lock shared by all hook processes.
+type Logger struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	redact     [][]string
+	scanner    *secrets.Scanner
+	onError    func(error)
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// NewLogger returns a logger writing to path. Nothing is created until the
+// first flush.
+func NewLogger(path string) *Logger {
+	l := &Logger{
+		path:       path,
+		maxSize:    DefaultMaxSize,
+		maxBackups: DefaultMaxBackups,
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "audit: %v\n", err)
+		},
+	}
+	types.OnExit(func() {
+		if err := l.Flush(); err != nil {
+			l.onError(err)
+		}
+	})
+	return l
+}
+
+// FromEnv returns a logger writing to $CLAUDE_HOOKS_AUDIT_FILE, or nil if
+// the variable is unset.
+func FromEnv() *Logger {
+	path := os.Getenv(FileEnv)
+	if path == "" {
+		return nil
+	}
+	return NewLogger(path)
+}
+
+// WithMaxSize sets the size in bytes at which the file is rotated. Zero
+// disables rotation.
+func (l *Logger) WithMaxSize(size int64) *Logger {
+	l.maxSize = size
+	return l
+}
+
+func (l *Logger) WithMaxBackups(n int) *Logger {
+	l.maxBackups = n
+	return l
+}
+
+// WithRedaction replaces the values at the given dotted paths of each
+// record with "[REDACTED]", e.g. "input.tool_input.content" or
+// "handlers.*.output". A "*" segment matches any key or index. Paths under
+// "handlers" and "reason" also apply to the shadow record, and paths
+// covering "input.prompt", "output.modifiedPrompt" or
+// "output.hookSpecificOutput.additionalContext" to the prompt record, since
+// those repeat them.
+func (l *Logger) WithRedaction(paths ...string) *Logger {
+	for _, path := range paths {
+		segments := strings.Split(path, ".")
+		l.redact = append(l.redact, segments)
+		if segments[0] == "handlers" || segments[0] == "reason" {
+			l.redact = append(l.redact, append([]string{"shadow"}, segments...))
+		}
+		for _, m := range promptMirrors {
+			if covers(segments, m.from) {
+				l.redact = append(l.redact, m.to)
+			}
+		}
+	}
+	return l
+}
+
+// promptMirrors maps the fields of a record to where its prompt record
+// repeats them.
+var promptMirrors = []struct{ from, to []string }{
+	{[]string{"input", "prompt"}, []string{"prompt", "original"}},
+	{[]string{"output", "modifiedPrompt"}, []string{"prompt", "rewritten"}},
+	{[]string{"output", "hookSpecificOutput", "additionalContext"}, []string{"prompt", "rewritten"}},
+}
+
+// covers reports whether redacting path redacts the field at target, that
+// is whether path leads to target or to an object holding it.
+func covers(path, target []string) bool {
+	if len(path) > len(target) {
+		return false
+	}
+	for i, segment := range path {
+		if segment != "*" && segment != target[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithSecretRedaction masks every secret the scanner finds in the string
+// values of each record. A nil scanner uses secrets.NewScanner().
+func (l *Logger) WithSecretRedaction(scanner *secrets.Scanner) *Logger {
+	if scanner == nil {
+		scanner = secrets.NewScanner()
+	}
+	l.scanner = scanner
+	return l
+}
+
+// WithErrorHandler sets the function receiving errors from Observe and from
+// the automatic flush at exit. The default writes them to stderr.
+func (l *Logger) WithErrorHandler(fn func(error)) *Logger {
+	l.onError = fn
+	return l
+}
+
+func (l *Logger) Path() string {
+	return l.path
+}
+
+func (l *Logger) Observe(inv handler.Invocation) {
+	if err := l.Write(NewRecord(inv)); err != nil {
+		l.onError(err)
+	}
+}
+
+// Write buffers a record.
+func (l *Logger) Write(rec Record) error {
+	line, err := l.encode(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode record: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf.Write(line)
+	return nil
+}
+
+func (l *Logger) encode(rec Record) ([]byte, error) {
+	if len(l.redact) == 0 && l.scanner == nil {
+		line, err := json.Marshal(rec)
+		return append(line, '\n'), err
+	}
+	tree, err := toTree(rec)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range l.redact {
+		redactPath(tree, path)
+	}
+	if l.scanner != nil {
+		tree = redactSecrets(tree, l.scanner)
+	}
+	line, err := json.Marshal(tree)
+	return append(line, '\n'), err
+}
+
+// Flush writes buffered records to the file, rotating it first if needed.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.buf.Len() == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	lock, err := filelock.Acquire(context.Background(), l.path+".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if err := l.rotate(int64(l.buf.Len())); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(l.buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	l.buf.Reset()
+	return nil
+}
+
+// rotate shifts the backups if appending pending bytes would exceed the
+// maximum size. The caller holds the file lock.
+func (l *Logger) rotate(pending int64) error {
+	if l.maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat audit log: %w", err)
+	}
+	if info.Size() == 0 || info.Size()+pending <= l.maxSize {
+		return nil
+	}
+
+	if l.maxBackups <= 0 {
+		if err := os.Remove(l.path); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+		return nil
+	}
+	os.Remove(backupName(l.path, l.maxBackups))
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(l.path, i), backupName(l.path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+	}
+	if err := os.Rename(l.path, backupName(l.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
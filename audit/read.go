@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Read decodes the records of an audit log in order, calling fn for each.
+func Read(r io.Reader, fn func(Record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ReadFiles reads the audit log at path including its rotated backups,
+// oldest first.
+func ReadFiles(path string, fn func(Record) error) error {
+	var paths []string
+	for i := 1; ; i++ {
+		if _, err := os.Stat(backupName(path, i)); err != nil {
+			break
+		}
+		paths = append([]string{backupName(path, i)}, paths...)
+	}
+	paths = append(paths, path)
+
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if errors.Is(err, os.ErrNotExist) && p == path && len(paths) > 1 {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = Read(f, fn)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Package audit records every routed event as one JSON line: the input,
+// each handler's individual result, the resolved decision and the time
+// spent. Register a Logger on a Router with WithObserver.
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/rewrite"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Record is one line of the audit log.
+type Record struct {
+	Time         time.Time       `json:"time"`
+	InvocationID string          `json:"invocation_id"`
+	SessionID    string          `json:"session_id"`
+	CWD          string          `json:"cwd"`
+	Event        types.EventName `json:"event"`
+	ToolName     types.ToolName  `json:"tool_name,omitempty"`
+	Input        interface{}     `json:"input"`
+	Handlers     []HandlerRecord `json:"handlers"`
+	Decision     string          `json:"decision"`
+	Reason       string          `json:"reason,omitempty"`
+	ExitCode     int             `json:"exit_code"`
+	Output       interface{}     `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	DurationMS   float64         `json:"duration_ms"`
+	Shadow       *ShadowRecord   `json:"shadow,omitempty"`
+	Prompt       *PromptRecord   `json:"prompt,omitempty"`
+}
+
+// HandlerRecord is the result of one handler. Handlers that did not run,
+// because an earlier one blocked in sync or pipeline mode, are omitted.
+type HandlerRecord struct {
+	Index      int         `json:"index"`
+	Name       string      `json:"name"`
+	Decision   string      `json:"decision"`
+	Reason     string      `json:"reason,omitempty"`
+	ExitCode   int         `json:"exit_code"`
+	Output     interface{} `json:"output,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	DurationMS float64     `json:"duration_ms"`
+}
+
+// ShadowRecord is what the shadow handlers of the event would have decided.
+// Diverges is set when that differs from the live decision, TimedOut when
+// the router stopped waiting for them.
+type ShadowRecord struct {
+	Decision string          `json:"decision"`
+	Reason   string          `json:"reason,omitempty"`
+	Diverges bool            `json:"diverges"`
+	TimedOut bool            `json:"timed_out,omitempty"`
+	Error    string          `json:"error,omitempty"`
+	Handlers []HandlerRecord `json:"handlers"`
+}
+
+// PromptRecord is set when a rewrite.Chain or the resolved output of a
+// UserPromptSubmit event rewrote the prompt.
+type PromptRecord struct {
+	Original  string `json:"original"`
+	Rewritten string `json:"rewritten"`
+}
+
+// NewRecord builds the record of an invocation.
+func NewRecord(inv handler.Invocation) Record {
+	rec := Record{
+		Time:         inv.Start.UTC(),
+		InvocationID: inv.ID,
+		SessionID:    inv.Input.GetSessionID(),
+		CWD:          inv.Input.GetCWD(),
+		Event:        inv.EventName,
+		ToolName:     toolName(inv.Input),
+		Input:        inv.Input,
+		Handlers:     handlerRecords(inv.Handlers, inv.Results),
+		Output:       inv.Output,
+		DurationMS:   milliseconds(inv.Duration),
+	}
+	rec.Decision, rec.Reason = handler.Outcome(inv.Output, inv.Err)
+	rec.ExitCode = exitCode(inv.Output, inv.Err)
+	rec.Prompt = promptRecord(inv)
+	if inv.Err != nil {
+		rec.Error = inv.Err.Error()
+	}
+	if shadow := inv.Shadow; shadow != nil {
+		rec.Shadow = &ShadowRecord{
+			Diverges: shadow.Diverges(inv.Output, inv.Err),
+			TimedOut: shadow.TimedOut,
+			Handlers: handlerRecords(shadow.Handlers, shadow.Results),
+		}
+		rec.Shadow.Decision, rec.Shadow.Reason = handler.Outcome(shadow.Output, shadow.Err)
+		if shadow.Err != nil {
+			rec.Shadow.Error = shadow.Err.Error()
+		}
+	}
+	return rec
+}
+
+func handlerRecords(handlers []handler.Handler, results []handler.HandlerResult) []HandlerRecord {
+	records := make([]HandlerRecord, 0, len(results))
+	for _, result := range results {
+		hr := HandlerRecord{
+			Index:      result.Index,
+			Output:     result.Output,
+			ExitCode:   exitCode(result.Output, result.Error),
+			DurationMS: milliseconds(result.Duration),
+		}
+		if result.Index < len(handlers) {
+			hr.Name = handler.Name(handlers[result.Index])
+		}
+		hr.Decision, hr.Reason = handler.Outcome(result.Output, result.Error)
+		if result.Error != nil {
+			hr.Error = result.Error.Error()
+		}
+		records = append(records, hr)
+	}
+	return records
+}
+
+// promptRecord takes the rewrite from the note of a rewrite.Chain, or from
+// the modifiedPrompt of the resolved output of other handlers.
+func promptRecord(inv handler.Invocation) *PromptRecord {
+	in, ok := inv.Input.(types.UserPromptSubmitInput)
+	if !ok {
+		return nil
+	}
+	if note, ok := inv.Notes[rewrite.NoteKey].(rewrite.Rewrite); ok {
+		return &PromptRecord{Original: note.Original, Rewritten: note.Rewritten}
+	}
+	out, ok := inv.Output.(types.UserPromptSubmitOutput)
+	if !ok || out.ModifiedPrompt == nil || *out.ModifiedPrompt == in.Prompt {
+		return nil
+	}
+	return &PromptRecord{Original: in.Prompt, Rewritten: *out.ModifiedPrompt}
+}
+
+func toolName(input types.HookInput) types.ToolName {
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		return in.ToolName
+	case types.PostToolUseInput:
+		return in.ToolName
+	}
+	return ""
+}
+
+// exitCode mirrors what the process would exit with: 1 for errors, as
+// handler.Execute does.
+func exitCode(output types.HookOutput, err error) int {
+	if err != nil {
+		return 1
+	}
+	if output == nil {
+		return types.ExitSuccess
+	}
+	return output.ExitWith()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// toTree converts v to the generic form produced by encoding/json, so that
+// redaction can address any field.
+func toTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	err = json.Unmarshal(data, &tree)
+	return tree, err
+}
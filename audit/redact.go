@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/secrets"
+)
+
+const redacted = "[REDACTED]"
+
+// redactPath replaces the value at a dotted path in tree. A "*" segment
+// matches every key of an object or element of an array.
+func redactPath(tree interface{}, path []string) {
+	if len(path) == 0 {
+		return
+	}
+	head, rest := path[0], path[1:]
+	switch node := tree.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if head != "*" && head != key {
+				continue
+			}
+			if len(rest) == 0 {
+				node[key] = redacted
+			} else {
+				redactPath(child, rest)
+			}
+		}
+	case []interface{}:
+		for i, child := range node {
+			if head != "*" && head != strconv.Itoa(i) {
+				continue
+			}
+			if len(rest) == 0 {
+				node[i] = redacted
+			} else {
+				redactPath(child, rest)
+			}
+		}
+	}
+}
+
+// redactSecrets replaces every secret the scanner finds in the string
+// values of tree.
+func redactSecrets(tree interface{}, scanner *secrets.Scanner) interface{} {
+	switch node := tree.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			node[key] = redactSecrets(child, scanner)
+		}
+	case []interface{}:
+		for i, child := range node {
+			node[i] = redactSecrets(child, scanner)
+		}
+	case string:
+		return redactString(node, scanner)
+	}
+	return tree
+}
+
+func redactString(s string, scanner *secrets.Scanner) string {
+	findings := scanner.Scan("", s)
+	if len(findings) == 0 {
+		return s
+	}
+	var b strings.Builder
+	pos := 0
+	for _, f := range findings {
+		if f.Start < pos {
+			continue
+		}
+		b.WriteString(s[pos:f.Start])
+		b.WriteString("[REDACTED:" + f.RuleID + "]")
+		pos = f.End
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"sort"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// ShadowSummary compares shadow and live decisions across audit records.
+type ShadowSummary struct {
+	Records  int `json:"records"`
+	Shadowed int `json:"shadowed"`
+	Diverged int `json:"diverged"`
+	TimedOut int `json:"timed_out"`
+	// Divergences groups diverging records by live and shadow decision,
+	// most frequent first.
+	Divergences []Divergence `json:"divergences"`
+
+	index       map[[2]string]int
+	maxExamples int
+}
+
+// Divergence counts records where the shadow handlers would have decided
+// Shadow while the live decision was Live.
+type Divergence struct {
+	Live     string                  `json:"live"`
+	Shadow   string                  `json:"shadow"`
+	Count    int                     `json:"count"`
+	Events   map[types.EventName]int `json:"events"`
+	Tools    map[types.ToolName]int  `json:"tools,omitempty"`
+	Examples []Record                `json:"examples"`
+}
+
+// NewShadowSummary returns an empty summary keeping up to maxExamples
+// records per divergence.
+func NewShadowSummary(maxExamples int) *ShadowSummary {
+	return &ShadowSummary{index: make(map[[2]string]int), maxExamples: maxExamples}
+}
+
+// Add counts a record.
+func (s *ShadowSummary) Add(rec Record) {
+	s.Records++
+	if rec.Shadow == nil {
+		return
+	}
+	s.Shadowed++
+	if rec.Shadow.TimedOut {
+		s.TimedOut++
+	}
+	if !rec.Shadow.Diverges {
+		return
+	}
+	s.Diverged++
+
+	key := [2]string{rec.Decision, rec.Shadow.Decision}
+	i, ok := s.index[key]
+	if !ok {
+		i = len(s.Divergences)
+		s.index[key] = i
+		s.Divergences = append(s.Divergences, Divergence{
+			Live:   rec.Decision,
+			Shadow: rec.Shadow.Decision,
+			Events: make(map[types.EventName]int),
+			Tools:  make(map[types.ToolName]int),
+		})
+	}
+	d := &s.Divergences[i]
+	d.Count++
+	d.Events[rec.Event]++
+	if rec.ToolName != "" {
+		d.Tools[rec.ToolName]++
+	}
+	if len(d.Examples) < s.maxExamples {
+		d.Examples = append(d.Examples, rec)
+	}
+}
+
+// Sort orders divergences by count, most frequent first.
+func (s *ShadowSummary) Sort() {
+	sort.SliceStable(s.Divergences, func(i, j int) bool {
+		return s.Divergences[i].Count > s.Divergences[j].Count
+	})
+	for i, d := range s.Divergences {
+		s.index[[2]string{d.Live, d.Shadow}] = i
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+)
+
+func runBuild(args []string) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	out := fs.String("o", "", "output binary (default: the package directory's name)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: hookctl build [-o binary] [package]")
+		fmt.Fprintln(fs.Output(), "\nBuilds a static dispatcher binary from a main package that runs a Router.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	pkg := "."
+	if fs.NArg() > 0 {
+		pkg = fs.Arg(0)
+	}
+	if *out == "" {
+		dir, err := filepath.Abs(pkg)
+		if err != nil {
+			return err
+		}
+		*out = filepath.Base(dir)
+	}
+
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags=-s -w", "-o", *out, pkg)
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go build: %w", err)
+	}
+
+	manifest, err := readManifest(*out)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Events) == 0 {
+		return fmt.Errorf("%s registers no handlers", *out)
+	}
+	fmt.Printf("built %s\n", *out)
+	for _, ev := range manifest.Events {
+		matcher := ev.Matcher
+		if matcher == "" {
+			matcher = "*"
+		}
+		fmt.Printf("  %-17s %-12s %v\n", ev.Event, matcher, ev.Handlers)
+	}
+	return nil
+}
+
+// readManifest asks a hook binary which events it handles.
+func readManifest(binary string) (handler.Manifest, error) {
+	var manifest handler.Manifest
+	path, err := filepath.Abs(binary)
+	if err != nil {
+		return manifest, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = append(os.Environ(), handler.ManifestEnv+"=1")
+	cmd.Stderr = os.Stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return manifest, fmt.Errorf("%s: failed to read manifest: %w", binary, err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("%s does not print a hook manifest; is it built with handler.Execute or daemon.Run? (%v)", binary, err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/config"
+)
+
+type layerStatus struct {
+	config.Layer
+	Present bool `json:"present"`
+}
+
+type configReport struct {
+	Root     string           `json:"root"`
+	Layers   []layerStatus    `json:"layers"`
+	Values   []config.Value   `json:"values"`
+	Ignored  []config.Ignored `json:"ignored,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	project := fs.String("project", ".", "directory the hooks run in")
+	file := fs.String("file", "", "single configuration file to read instead of the layers")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(*project)
+	if err != nil {
+		return err
+	}
+
+	loader := config.NewLoader(*file)
+	rep := configReport{Root: config.ProjectRoot(dir)}
+	for _, layer := range loader.Layers(dir) {
+		_, err := os.Stat(layer.Path)
+		present := err == nil
+		rep.Layers = append(rep.Layers, layerStatus{layer, present})
+		if present && layer.Name == config.LayerLocal && !gitIgnored(rep.Root, layer.Path) {
+			rep.Warnings = append(rep.Warnings, fmt.Sprintf("%s is not ignored by git; it is meant for personal settings", layer.Path))
+		}
+	}
+	c, loadErr := config.LoadLayers(loader.Layers(dir))
+	if loadErr != nil {
+		rep.Error = loadErr.Error()
+	} else {
+		rep.Values = c.Values()
+		rep.Ignored = c.Ignored
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			return err
+		}
+	} else {
+		printConfig(rep)
+	}
+	if loadErr != nil {
+		return errors.New("configuration does not validate")
+	}
+	return nil
+}
+
+func printConfig(rep configReport) {
+	fmt.Printf("Project root: %s\n\nLayers, lowest precedence first:\n", rep.Root)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, layer := range rep.Layers {
+		if layer.Present {
+			fmt.Fprintf(w, "  %s\t%s\n", layer.Name, layer.Path)
+		} else {
+			fmt.Fprintf(w, "  %s\t%s\t(not present)\n", layer.Name, layer.Path)
+		}
+	}
+	w.Flush()
+
+	for _, warning := range rep.Warnings {
+		fmt.Printf("\nwarning: %s\n", warning)
+	}
+	if rep.Error != "" {
+		fmt.Printf("\nerror: %s\n", rep.Error)
+		return
+	}
+
+	fmt.Println("\nEffective configuration:")
+	if len(rep.Values) == 0 {
+		fmt.Println("  (none; the router keeps the settings it was built with)")
+	} else {
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, v := range rep.Values {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", v.Key, v.Value, v.Source)
+		}
+		w.Flush()
+	}
+
+	if len(rep.Ignored) > 0 {
+		fmt.Println("\nIgnored, since the project and local layers may only tighten:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, v := range rep.Ignored {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", v.Key, v.Value.Value, v.Source, v.Reason)
+		}
+		w.Flush()
+	}
+}
+
+// gitIgnored reports whether git ignores path. Outside a git repository,
+// or without git, every path counts as ignored.
+func gitIgnored(root, path string) bool {
+	err := exec.Command("git", "-C", root, "check-ignore", "-q", path).Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == 1 {
+		return false
+	}
+	return true
+}
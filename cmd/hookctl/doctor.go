@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/settings"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/shell"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/simulate"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// report collects the findings of doctor checks.
+type report struct {
+	problems, warnings int
+}
+
+func (r *report) ok(format string, args ...interface{}) {
+	fmt.Printf("  ok    %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *report) warn(format string, args ...interface{}) {
+	r.warnings++
+	fmt.Printf("  warn  %s\n", fmt.Sprintf(format, args...))
+}
+
+func (r *report) fail(format string, args ...interface{}) {
+	r.problems++
+	fmt.Printf("  FAIL  %s\n", fmt.Sprintf(format, args...))
+}
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	var files stringList
+	fs.Var(&files, "settings", "settings file to check; repeatable (default: the user, project and local settings)")
+	project := fs.String("project", ".", "project directory")
+	run := fs.Bool("run", false, "also run each hook with a synthetic payload for its events; the hooks run for real in the project")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(*project)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		for _, scope := range settings.Scopes {
+			path, err := settings.Path(scope, dir)
+			if err != nil {
+				return err
+			}
+			files = append(files, path)
+		}
+	}
+
+	if *run {
+		// Keep the state of the synthetic session out of the real one.
+		stateDir, err := os.MkdirTemp("", "hookctl-doctor-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(stateDir)
+		os.Setenv(state.DirEnv, stateDir)
+	}
+
+	r := &report{}
+	var hooks []simulate.Hook
+	for _, path := range files {
+		hooks = append(hooks, checkSettings(r, path)...)
+	}
+
+	checked := make(map[string]bool)
+	for _, h := range hooks {
+		key := string(h.Event) + "\x00" + h.Matcher + "\x00" + h.Command.Command
+		if checked[key] {
+			continue
+		}
+		checked[key] = true
+		matcher := h.Matcher
+		if matcher == "" {
+			matcher = "*"
+		}
+		fmt.Printf("\n%s [%s] %s\n", h.Event, matcher, h.Command.Command)
+		if !checkExecutable(r, h, dir) || !*run {
+			continue
+		}
+		checkResponse(r, h, dir)
+	}
+
+	fmt.Println()
+	if r.problems > 0 {
+		return fmt.Errorf("%d problem(s), %d warning(s)", r.problems, r.warnings)
+	}
+	fmt.Printf("no problems, %d warning(s)\n", r.warnings)
+	return nil
+}
+
+// checkSettings validates a settings file and returns its hooks.
+func checkSettings(r *report, path string) []simulate.Hook {
+	fmt.Printf("%s\n", path)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		r.ok("not present")
+		return nil
+	}
+	if err != nil {
+		r.fail("%v", err)
+		return nil
+	}
+
+	f, err := settings.Parse(path, data)
+	if err != nil {
+		r.fail("invalid JSON%s: %v", location(data, err), errors.Unwrap(err))
+		return nil
+	}
+	hooks, err := simulate.Configured(f)
+	if err != nil {
+		r.fail("invalid hooks section: %v", err)
+		return nil
+	}
+
+	events := make(map[types.EventName]bool)
+	valid := hooks[:0]
+	for _, h := range hooks {
+		switch {
+		case !h.Event.IsValid():
+			r.fail("unknown event %q", h.Event)
+			continue
+		case h.Command.Type != "command":
+			r.warn("%s hook %q has type %q; only \"command\" hooks are checked", h.Event, h.Command.Command, h.Command.Type)
+			continue
+		case strings.TrimSpace(h.Command.Command) == "":
+			r.fail("%s hook with an empty command", h.Event)
+			continue
+		}
+		if !validMatcher(h.Matcher) {
+			r.warn("%s matcher %q is not a valid regular expression and only matches literally", h.Event, h.Matcher)
+		}
+		valid = append(valid, h)
+		events[h.Event] = true
+	}
+	r.ok("valid JSON, %d hook(s) for %d event(s)", len(valid), len(events))
+	return valid
+}
+
+// location turns the offset of a JSON error into a line and column.
+func location(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return ""
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf(" at line %d, column %d", line, column)
+}
+
+func validMatcher(matcher string) bool {
+	if matcher == "" || matcher == "*" {
+		return true
+	}
+	_, err := simulate.CompileMatcher(matcher)
+	return err == nil
+}
+
+// builtins are the shell builtins commonly found in front of a hook's
+// program, as in `cd "$CLAUDE_PROJECT_DIR" && ./hook`.
+var builtins = map[string]bool{
+	"cd": true, "pushd": true, "popd": true, "export": true, "unset": true,
+	"set": true, "source": true, ".": true, "alias": true, ":": true, "true": true,
+}
+
+// checkExecutable resolves the program a hook command runs, the first
+// command that is not a shell builtin, and reports whether it exists and
+// can be executed. Relative paths are resolved against the project
+// directory, following a preceding cd.
+func checkExecutable(r *report, h simulate.Hook, dir string) bool {
+	script, err := shell.Parse(h.Command.Command)
+	if err != nil {
+		r.fail("command does not parse: %v", err)
+		return false
+	}
+	project := dir
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			if name == "CLAUDE_PROJECT_DIR" {
+				return project
+			}
+			return os.Getenv(name)
+		})
+	}
+	var program *shell.Command
+	for _, cmd := range script.Commands() {
+		resolved := cmd.Resolve()
+		if !builtins[resolved.Name] {
+			program = cmd
+			break
+		}
+		if operands := resolved.Operands(); resolved.Name == "cd" && len(operands) > 0 {
+			target := expand(operands[0].Value)
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			dir = target
+		}
+	}
+	if program == nil {
+		r.fail("command runs no program")
+		return false
+	}
+	path := expand(program.Resolve().Path)
+	if path == "" || strings.ContainsAny(path, "$`") {
+		r.warn("cannot resolve the program of %q; it is run but not inspected", h.Command.Command)
+		return true
+	}
+
+	if !strings.ContainsRune(path, filepath.Separator) && !strings.Contains(path, "/") {
+		found, err := exec.LookPath(path)
+		if err != nil {
+			r.fail("%s is not on PATH", path)
+			return false
+		}
+		r.ok("%s resolves to %s", path, found)
+		return true
+	}
+	if !filepath.IsAbs(path) {
+		// The host runs hooks from the project directory.
+		path = filepath.Join(dir, path)
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		r.fail("%s does not exist", path)
+		return false
+	case info.IsDir():
+		r.fail("%s is a directory", path)
+		return false
+	case runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0:
+		r.fail("%s is not executable (mode %v)", path, info.Mode().Perm())
+		return false
+	}
+	r.ok("%s is executable", path)
+	return true
+}
+
+// checkResponse runs a hook with a harmless payload for its event and
+// checks that it answers in time with output the host can read.
+func checkResponse(r *report, h simulate.Hook, dir string) {
+	e, ok := simulate.Synthetic(h.Event, h.Matcher, dir)
+	if !ok {
+		r.warn("no known tool, trigger or source matches %q; not run", h.Matcher)
+		return
+	}
+	payload, err := e.Payload()
+	if err != nil {
+		r.fail("%v", err)
+		return
+	}
+	result := simulate.Run(context.Background(), []simulate.Hook{h}, payload, dir)[0]
+	subject := string(h.Event)
+	if e.ToolName != "" {
+		subject += " " + string(e.ToolName)
+	}
+
+	switch {
+	case result.TimedOut:
+		r.fail("no answer to a %s payload within the %v timeout", subject, h.Timeout())
+		return
+	case result.Error != "":
+		r.fail("failed to start: %s", result.Error)
+		return
+	case result.ExitCode == types.ExitBlocking:
+		r.warn("blocks a harmless %s payload: %s", subject, result.Reason)
+	case result.ExitCode != 0:
+		r.fail("exits with %d on a %s payload: %s", result.ExitCode, subject, firstLine(result.Stderr))
+		return
+	default:
+		r.ok("answers a %s payload in %v", subject, result.Duration.Round(time.Millisecond))
+	}
+	if limit := h.Timeout(); result.Duration > limit/2 {
+		r.warn("took %v, more than half of its %v timeout", result.Duration.Round(time.Millisecond), limit)
+	}
+	checkStdout(r, h.Event, result.Stdout)
+}
+
+func checkStdout(r *report, event types.EventName, stdout string) {
+	trimmed := strings.TrimSpace(stdout)
+	switch {
+	case trimmed == "":
+		r.ok("no output")
+		return
+	case !strings.HasPrefix(trimmed, "{"):
+		if i := strings.Index(trimmed, "{"); i > 0 {
+			r.fail("stray text on stdout before the JSON output: %q", firstLine(trimmed[:i]))
+			return
+		}
+		if event == types.EventUserPromptSubmit || event == types.EventSessionStart {
+			r.ok("plain text output, added to the context by the host")
+			return
+		}
+		r.warn("stdout is not JSON and is ignored by the host: %q", firstLine(trimmed))
+		return
+	}
+	if _, err := types.ParseOutput(event, []byte(trimmed)); err != nil {
+		r.fail("stdout is not a valid %sOutput: %v", event, err)
+		return
+	}
+	r.ok("stdout is a valid %sOutput", event)
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/settings"
+)
+
+type installFlags struct {
+	fs      *flag.FlagSet
+	scope   *string
+	project *string
+	file    *string
+	command *string
+	dryRun  *bool
+}
+
+func newInstallFlags(name, usage string) *installFlags {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	f := &installFlags{
+		fs:      fs,
+		scope:   fs.String("scope", "project", "settings file to edit: user, project or local"),
+		project: fs.String("project", ".", "project directory for the project and local scopes"),
+		file:    fs.String("settings", "", "edit this settings file instead of the scope's"),
+		command: fs.String("command", "", "command written to the settings (default: the binary's absolute path)"),
+		dryRun:  fs.Bool("dry-run", false, "print a diff of the settings file instead of writing it"),
+	}
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	return f
+}
+
+// parse returns the binary argument, the command registered for it and the
+// settings file to edit.
+func (f *installFlags) parse(args []string) (string, string, *settings.File, error) {
+	if err := f.fs.Parse(args); err != nil {
+		return "", "", nil, err
+	}
+	if f.fs.NArg() != 1 {
+		f.fs.Usage()
+		return "", "", nil, errors.New("expected one hook binary")
+	}
+	binary := f.fs.Arg(0)
+	command := *f.command
+	if command == "" {
+		abs, err := filepath.Abs(binary)
+		if err != nil {
+			return "", "", nil, err
+		}
+		command = abs
+	}
+
+	path := *f.file
+	if path == "" {
+		project, err := filepath.Abs(*f.project)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if path, err = settings.Path(settings.Scope(*f.scope), project); err != nil {
+			return "", "", nil, err
+		}
+	}
+	file, err := settings.Load(path)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return binary, command, file, nil
+}
+
+func runInstall(args []string) error {
+	f := newInstallFlags("install", "Usage: hookctl install [flags] <hook-binary>\n\nRegisters the binary in settings.json for every event its router handles.")
+	binary, command, file, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	manifest, err := readManifest(binary)
+	if err != nil {
+		return err
+	}
+	if len(manifest.Events) == 0 {
+		return fmt.Errorf("%s registers no handlers", binary)
+	}
+	before, err := currentContents(file.Path)
+	if err != nil {
+		return err
+	}
+	if err := file.Install(command, manifest); err != nil {
+		return err
+	}
+	return writeSettings(file, before, *f.dryRun)
+}
+
+func runUninstall(args []string) error {
+	f := newInstallFlags("uninstall", "Usage: hookctl uninstall [flags] <hook-binary>\n\nRemoves the binary's hooks from settings.json.")
+	_, command, file, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	before, err := currentContents(file.Path)
+	if err != nil {
+		return err
+	}
+	if err := file.Uninstall(command); err != nil {
+		return err
+	}
+	return writeSettings(file, before, *f.dryRun)
+}
+
+func currentContents(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func writeSettings(file *settings.File, before []byte, dryRun bool) error {
+	after, err := file.Marshal()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		fmt.Fprintf(os.Stderr, "%s is up to date\n", file.Path)
+		return nil
+	}
+	if dryRun {
+		os.Stdout.WriteString(unifiedDiff(file.Path, string(before), string(after)))
+		return nil
+	}
+	if err := file.Save(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "updated %s\n", file.Path)
+	return nil
+}
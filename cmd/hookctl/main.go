@@ -0,0 +1,65 @@
+// Command hookctl is a companion tool for hooks built with the SDK.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"build":         {summary: "build a single dispatcher binary from a main package running a Router", run: runBuild},
+	"config":        {summary: "print the effective hook configuration and where each value comes from", run: runConfig},
+	"doctor":        {summary: "check settings files and the hooks they reference", run: runDoctor},
+	"install":       {summary: "register a hook binary in a settings.json file", run: runInstall},
+	"metrics":       {summary: "render aggregated hook metrics for Prometheus or serve them over HTTP", run: runMetrics},
+	"schema":        {summary: "generate JSON Schema documents for hook inputs and outputs", run: runSchema},
+	"shadow-report": {summary: "summarize where shadow handlers diverged from live decisions in an audit log", run: runShadowReport},
+	"simulate":      {summary: "run the configured hooks for an event the way the host would", run: runSimulate},
+	"uninstall":     {summary: "remove a hook binary from a settings.json file", run: runUninstall},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "hookctl: unknown command %q\n", name)
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "hookctl %s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: hookctl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].summary)
+	}
+}
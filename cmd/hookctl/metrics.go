@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/metrics"
+)
+
+func runMetrics(args []string) error {
+	fs := flag.NewFlagSet("metrics", flag.ContinueOnError)
+	file := fs.String("file", os.Getenv(metrics.FileEnv), "metrics file written by the hooks (default: $"+metrics.FileEnv+")")
+	format := fs.String("format", "prometheus", "output format: prometheus or openmetrics")
+	out := fs.String("out", "", "write a node_exporter textfile (.prom) instead of printing")
+	every := fs.Duration("every", 0, "with -out, rewrite the textfile at this interval until interrupted")
+	listen := fs.String("listen", "", "serve /metrics on this address, e.g. 127.0.0.1:9464")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("no metrics file: pass -file or set %s", metrics.FileEnv)
+	}
+
+	switch {
+	case *listen != "":
+		fmt.Fprintf(os.Stderr, "serving %s on http://%s/metrics\n", *file, *listen)
+		return metrics.ListenAndServe(*listen, *file)
+	case *out != "":
+		for {
+			if err := metrics.WriteTextfile(*file, *out); err != nil {
+				return err
+			}
+			if *every <= 0 {
+				return nil
+			}
+			time.Sleep(*every)
+		}
+	}
+
+	f, err := metrics.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	snapshot, err := metrics.Load(*file)
+	if err != nil {
+		return err
+	}
+	return snapshot.Write(os.Stdout, f)
+}
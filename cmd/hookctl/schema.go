@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/schema"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+func runSchema(args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	out := fs.String("out", "", "directory to write one file per event and direction (default: print to stdout)")
+	event := fs.String("event", "", "only emit schemas for this event")
+	check := fs.Bool("check", false, "verify that the files in -out match the Go types instead of writing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	docs, err := schema.All()
+	if err != nil {
+		return err
+	}
+	if *event != "" {
+		in, output, err := schema.ForEvent(types.EventName(*event))
+		if err != nil {
+			return err
+		}
+		docs = []schema.Document{in, output}
+	}
+
+	if *out == "" {
+		if *check {
+			return fmt.Errorf("-check requires -out")
+		}
+		for _, doc := range docs {
+			data, err := doc.Marshal()
+			if err != nil {
+				return err
+			}
+			os.Stdout.Write(data)
+		}
+		return nil
+	}
+
+	if !*check {
+		if err := os.MkdirAll(*out, 0o755); err != nil {
+			return err
+		}
+	}
+
+	var stale []string
+	for _, doc := range docs {
+		data, err := doc.Marshal()
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(*out, doc.FileName())
+
+		if *check {
+			existing, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(existing, data) {
+				stale = append(stale, path)
+			}
+			continue
+		}
+
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	if len(stale) > 0 {
+		for _, path := range stale {
+			fmt.Fprintf(os.Stderr, "out of date: %s\n", path)
+		}
+		return fmt.Errorf("%d schema file(s) out of date; run 'go generate ./schema'", len(stale))
+	}
+	return nil
+}
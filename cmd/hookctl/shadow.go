@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/audit"
+)
+
+func runShadowReport(args []string) error {
+	fs := flag.NewFlagSet("shadow-report", flag.ContinueOnError)
+	file := fs.String("file", os.Getenv(audit.FileEnv), "audit log written by the hooks, read with its rotated backups (default: $"+audit.FileEnv+")")
+	since := fs.Duration("since", 0, "only consider records newer than this, e.g. 24h")
+	examples := fs.Int("examples", 3, "example records to show per divergence")
+	asJSON := fs.Bool("json", false, "print the summary as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("no audit log: pass -file or set %s", audit.FileEnv)
+	}
+
+	var cutoff time.Time
+	if *since > 0 {
+		cutoff = time.Now().Add(-*since)
+	}
+	summary := audit.NewShadowSummary(*examples)
+	err := audit.ReadFiles(*file, func(rec audit.Record) error {
+		if rec.Time.After(cutoff) {
+			summary.Add(rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	summary.Sort()
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+
+	fmt.Printf("%d records, %d with shadow handlers, %d diverged", summary.Records, summary.Shadowed, summary.Diverged)
+	if summary.Shadowed > 0 {
+		fmt.Printf(" (%.1f%%)", 100*float64(summary.Diverged)/float64(summary.Shadowed))
+	}
+	if summary.TimedOut > 0 {
+		fmt.Printf(", %d timed out", summary.TimedOut)
+	}
+	fmt.Println()
+	for _, d := range summary.Divergences {
+		fmt.Printf("\nlive %s, shadow %s: %d\n", d.Live, d.Shadow, d.Count)
+		fmt.Printf("  events: %s\n", counts(d.Events))
+		if len(d.Tools) > 0 {
+			fmt.Printf("  tools:  %s\n", counts(d.Tools))
+		}
+		for _, rec := range d.Examples {
+			fmt.Printf("  %s  %s", rec.Time.Local().Format(time.DateTime), rec.InvocationID)
+			if rec.ToolName != "" {
+				fmt.Printf("  %s", rec.ToolName)
+			}
+			if rec.Shadow.Reason != "" {
+				fmt.Printf("  %q", rec.Shadow.Reason)
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+// counts renders a count map as "a 3, b 1", largest first.
+func counts[K ~string](m map[K]int) string {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s %d", k, m[k])
+	}
+	return strings.Join(parts, ", ")
+}
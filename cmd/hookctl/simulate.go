@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/settings"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/simulate"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+func runSimulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	var files stringList
+	fs.Var(&files, "settings", "settings file to read; repeatable (default: the user, project and local settings)")
+	project := fs.String("project", ".", "project directory; hooks run there with CLAUDE_PROJECT_DIR set to it")
+	inputFile := fs.String("input", "", "read the event payload from this file, or - for stdin, instead of building it from flags")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+
+	event := fs.String("event", string(types.EventPreToolUse), "event to simulate")
+	tool := fs.String("tool", string(types.ToolBash), "tool name for tool events")
+	command := fs.String("command", "", "Bash command (tool_input.command)")
+	filePath := fs.String("file", "", "file path (tool_input.file_path)")
+	content := fs.String("content", "", "file content (tool_input.content)")
+	toolInput := fs.String("tool-input", "", "tool_input as a JSON object, merged under the flags above")
+	toolResponse := fs.String("tool-response", "", "tool_response as JSON for PostToolUse")
+	prompt := fs.String("prompt", "", "prompt for UserPromptSubmit")
+	message := fs.String("message", "", "message for Notification")
+	trigger := fs.String("trigger", string(types.CompactTriggerManual), "trigger for PreCompact")
+	source := fs.String("source", string(types.SessionSourceStartup), "source for SessionStart")
+	stopActive := fs.Bool("stop-hook-active", false, "stop_hook_active for Stop and SubagentStop")
+	session := fs.String("session", "simulated-session", "session ID")
+	transcriptPath := fs.String("transcript", "", "transcript path")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	dir, err := filepath.Abs(*project)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		for _, scope := range settings.Scopes {
+			path, err := settings.Path(scope, dir)
+			if err != nil {
+				return err
+			}
+			files = append(files, path)
+		}
+	}
+	var loaded []*settings.File
+	for _, path := range files {
+		f, err := settings.Load(path)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, f)
+	}
+	configured, err := simulate.Configured(loaded...)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	if *inputFile != "" {
+		if payload, err = readInput(*inputFile); err != nil {
+			return err
+		}
+		payload = bytes.TrimSpace(payload)
+	} else {
+		e := simulate.Event{
+			Name:           types.EventName(*event),
+			SessionID:      *session,
+			TranscriptPath: *transcriptPath,
+			CWD:            dir,
+			ToolName:       types.ToolName(*tool),
+			ToolInput:      map[string]interface{}{},
+			Prompt:         *prompt,
+			Message:        *message,
+			Trigger:        types.CompactTrigger(*trigger),
+			Source:         types.SessionSource(*source),
+			StopHookActive: *stopActive,
+		}
+		if *toolInput != "" {
+			if err := json.Unmarshal([]byte(*toolInput), &e.ToolInput); err != nil {
+				return fmt.Errorf("-tool-input: %w", err)
+			}
+		}
+		for key, value := range map[string]string{"command": *command, "file_path": *filePath, "content": *content} {
+			if value != "" {
+				e.ToolInput[key] = value
+			}
+		}
+		if *toolResponse != "" {
+			if err := json.Unmarshal([]byte(*toolResponse), &e.ToolResponse); err != nil {
+				return fmt.Errorf("-tool-response: %w", err)
+			}
+		}
+		if payload, err = e.Payload(); err != nil {
+			return err
+		}
+	}
+
+	hooks, err := simulate.Select(configured, payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	results := simulate.Run(context.Background(), hooks, payload, dir)
+	decision, reason := simulate.Combine(results)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Payload  json.RawMessage   `json:"payload"`
+			Results  []simulate.Result `json:"results"`
+			Decision string            `json:"decision"`
+			Reason   string            `json:"reason,omitempty"`
+		}{payload, results, decision, reason})
+	}
+
+	fmt.Printf("payload: %s\n", payload)
+	fmt.Printf("%d of %d configured hooks matched\n", len(hooks), len(configured))
+	for i, r := range results {
+		matcher := r.Hook.Matcher
+		if matcher == "" {
+			matcher = "*"
+		}
+		fmt.Printf("\n[%d] %s\n", i+1, r.Hook.Command.Command)
+		fmt.Printf("    matcher %q from %s, timeout %v\n", matcher, r.Hook.Source, r.Hook.Timeout())
+		switch {
+		case r.TimedOut:
+			fmt.Printf("    timed out after %v\n", r.Duration.Round(time.Millisecond))
+		case r.Error != "":
+			fmt.Printf("    failed to start: %s\n", r.Error)
+		default:
+			fmt.Printf("    exit %d in %v\n", r.ExitCode, r.Duration.Round(time.Millisecond))
+		}
+		printStream("stdout", r.Stdout)
+		printStream("stderr", r.Stderr)
+		fmt.Printf("    decision: %s\n", describeDecision(r.Decision, r.Reason))
+	}
+	fmt.Printf("\ndecision: %s\n", describeDecision(decision, reason))
+	return nil
+}
+
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+func printStream(name, s string) {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return
+	}
+	fmt.Printf("    %s:\n", name)
+	for _, line := range strings.Split(s, "\n") {
+		fmt.Printf("      %s\n", line)
+	}
+}
+
+func describeDecision(decision, reason string) string {
+	if reason == "" {
+		return decision
+	}
+	return fmt.Sprintf("%s (%s)", decision, reason)
+}
@@ -0,0 +1,227 @@
+// Package config configures a Router from a YAML or JSON file: execution
+// and resolution modes, the timeout, which named handlers run, and policy
+// rules. Configuration is layered like the host's settings: a user file,
+// a project file and a personal local file, merged by LoadLayers.
+// Environment variables override the files, and a Loader reloads them
+// when they change, keeping the last good configuration when a new one
+// does not validate.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/policy"
+)
+
+// Environment variables read by Loader. DisableEnv and EnableEnv take
+// comma-separated handler names.
+const (
+	FileEnv       = "CLAUDE_HOOKS_CONFIG"
+	ExecutionEnv  = "CLAUDE_HOOKS_EXECUTION"
+	ResolutionEnv = "CLAUDE_HOOKS_RESOLUTION"
+	TimeoutEnv    = "CLAUDE_HOOKS_TIMEOUT"
+	DisableEnv    = "CLAUDE_HOOKS_DISABLE"
+	EnableEnv     = "CLAUDE_HOOKS_ENABLE"
+)
+
+// File is the on-disk representation of a configuration. Every field is
+// optional; unset fields keep what the Router was built with.
+//
+//	execution: async
+//	resolution: block_any
+//	timeout: 10s
+//	handlers:
+//	  ratelimit.Limiter: false   # names as reported by handler.Name
+//	policy:
+//	  default: allow
+//	  rules:
+//	    - name: no-recursive-rm
+//	      tools: [Bash]
+//	      conditions:
+//	        - field: tool_input.command
+//	          regex: '\brm\s+-[a-zA-Z]*r'
+//	      decision: deny
+type File struct {
+	Execution  string          `json:"execution,omitempty" yaml:"execution,omitempty"`
+	Resolution string          `json:"resolution,omitempty" yaml:"resolution,omitempty"`
+	Timeout    string          `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Handlers   map[string]bool `json:"handlers,omitempty" yaml:"handlers,omitempty"`
+	Policy     *policy.File    `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// Config is a validated configuration.
+type Config struct {
+	File
+	// Sources maps each value set by a layer or the environment to where
+	// it came from, under keys such as "timeout", "handlers.<name>",
+	// "policy.default" or "policy.rules.<name>", with "policy.rules.#<n>"
+	// for unnamed rules. It is only filled in by LoadLayers.
+	Sources map[string]Source
+	// Ignored lists the settings of the project and local layers that
+	// would have loosened the configuration. It is only filled in by
+	// LoadLayers.
+	Ignored []Ignored
+
+	execution  *handler.ExecutionMode
+	resolution *handler.ResolutionMode
+	timeout    time.Duration
+	policy     *policy.Engine
+}
+
+// Parse decodes a configuration file. Files ending in .yaml or .yml are
+// decoded as YAML, everything else as JSON. Unknown fields are rejected.
+func Parse(path string, data []byte) (File, error) {
+	var file File
+	if len(bytes.TrimSpace(data)) == 0 {
+		return file, nil
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return file, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&file); err != nil {
+			return file, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return file, nil
+}
+
+// ApplyEnv overrides the file with the CLAUDE_HOOKS_* variables that are
+// set.
+func (f *File) ApplyEnv() {
+	f.applyEnv(nil)
+}
+
+// applyEnv overrides the file from the environment, recording the variable
+// behind each value in sources if it is not nil.
+func (f *File) applyEnv(sources map[string]Source) {
+	for _, v := range []struct {
+		variable string
+		dst      *string
+		key      string
+	}{
+		{ExecutionEnv, &f.Execution, "execution"},
+		{ResolutionEnv, &f.Resolution, "resolution"},
+		{TimeoutEnv, &f.Timeout, "timeout"},
+	} {
+		if value := os.Getenv(v.variable); value != "" {
+			*v.dst = value
+			if sources != nil {
+				sources[v.key] = Source{Layer: LayerEnv, Variable: v.variable}
+			}
+		}
+	}
+	for _, variable := range []string{DisableEnv, EnableEnv} {
+		for _, name := range strings.Split(os.Getenv(variable), ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if f.Handlers == nil {
+				f.Handlers = make(map[string]bool)
+			}
+			f.Handlers[name] = variable == EnableEnv
+			if sources != nil {
+				sources["handlers."+name] = Source{Layer: LayerEnv, Variable: variable}
+			}
+		}
+	}
+}
+
+// Compile validates a file.
+func Compile(file File) (*Config, error) {
+	c := &Config{File: file}
+	if file.Execution != "" {
+		mode, err := handler.ParseExecutionMode(file.Execution)
+		if err != nil {
+			return nil, err
+		}
+		c.execution = &mode
+	}
+	if file.Resolution != "" {
+		mode, err := handler.ParseResolutionMode(file.Resolution)
+		if err != nil {
+			return nil, err
+		}
+		c.resolution = &mode
+	}
+	if file.Timeout != "" {
+		timeout, err := time.ParseDuration(file.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q: must be positive", file.Timeout)
+		}
+		c.timeout = timeout
+	}
+	for name := range file.Handlers {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("empty handler name")
+		}
+	}
+	if file.Policy != nil {
+		engine, err := policy.Compile(*file.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("policy: %w", err)
+		}
+		c.policy = engine
+	}
+	return c, nil
+}
+
+// LoadFile reads, overrides from the environment and validates a
+// configuration file. A missing file yields a configuration from the
+// environment alone.
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	file, err := Parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	file.ApplyEnv()
+	c, err := Compile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Apply returns a copy of base configured by c.
+func (c *Config) Apply(base *handler.Router) *handler.Router {
+	r := base.Clone()
+	if c.execution != nil {
+		r.WithExecution(*c.execution)
+	}
+	if c.resolution != nil {
+		r.WithResolution(*c.resolution)
+	}
+	if c.timeout > 0 {
+		r.WithTimeout(c.timeout)
+	}
+	for name, enabled := range c.Handlers {
+		if enabled {
+			r.Enable(name)
+		} else {
+			r.Disable(name)
+		}
+	}
+	return r
+}
@@ -0,0 +1,304 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/projectdir"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/policy"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Layer names, lowest precedence first.
+const (
+	LayerUser    = "user"
+	LayerProject = "project"
+	LayerLocal   = "local"
+	LayerFile    = "file"
+)
+
+// Layer is one configuration file.
+type Layer struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Base names of the layer files inside a .claude directory. The first
+// existing extension is used.
+const (
+	projectBase = projectdir.ConfigBase
+	localBase   = projectdir.LocalBase
+)
+
+// Layers returns the files configuring hooks run in cwd, lowest precedence
+// first, mirroring the host's settings:
+//
+//   - user: ~/.claude/hooks.yaml
+//   - project: <root>/.claude/hooks.yaml, meant to be committed
+//   - local: <root>/.claude/hooks.local.yaml, personal and gitignored
+//   - file: $CLAUDE_HOOKS_CONFIG, if set
+//
+// The project root is found from cwd by ProjectRoot. Each layer may also be
+// a .yml or .json file. Missing files are listed too, so that creating one
+// is noticed.
+func Layers(cwd string) []Layer {
+	var layers []Layer
+	if home, err := os.UserHomeDir(); err == nil {
+		layers = append(layers, Layer{LayerUser, find(filepath.Join(home, ".claude"), projectBase)})
+	}
+	if root := ProjectRoot(cwd); root != "" {
+		dir := filepath.Join(root, ".claude")
+		layers = append(layers,
+			Layer{LayerProject, find(dir, projectBase)},
+			Layer{LayerLocal, find(dir, localBase)})
+	}
+	if path := os.Getenv(FileEnv); path != "" {
+		layers = append(layers, Layer{LayerFile, path})
+	}
+	return layers
+}
+
+// find returns the existing layer file in dir, or the .yaml name.
+func find(dir, base string) string {
+	for _, ext := range projectdir.Extensions {
+		path := filepath.Join(dir, base+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, base+projectdir.Extensions[0])
+}
+
+// ProjectRoot returns the nearest directory at or above cwd that has hook
+// configuration in its .claude directory or is the root of a git
+// repository, or cwd itself if there is none. The home directory is never
+// a project root, since its .claude directory holds the user layer.
+func ProjectRoot(cwd string) string {
+	return projectdir.Root(cwd)
+}
+
+// Source describes where a value of the effective configuration came from:
+// a layer and its file, or an environment variable.
+type Source struct {
+	Layer    string `json:"layer"`
+	Path     string `json:"path,omitempty"`
+	Variable string `json:"variable,omitempty"`
+}
+
+// LayerEnv is the layer of values set through environment variables.
+const LayerEnv = "env"
+
+func (s Source) String() string {
+	if s.Variable != "" {
+		return s.Layer + " " + s.Variable
+	}
+	return s.Layer + " " + s.Path
+}
+
+// LoadLayers reads and merges layers in order, then applies the environment
+// overrides. Later layers win:
+//
+//   - execution, resolution, timeout and policy.default take the value of
+//     the last layer that sets them
+//   - handlers merge by name, the last layer setting a name wins
+//   - policy rules accumulate in layer order; a rule named like an earlier
+//     one replaces it in place
+//
+// The project and local layers live in the repository, so they may only
+// tighten what the other layers set: they cannot set the execution mode or
+// the timeout, disable handlers, choose a resolution other than block_any,
+// loosen policy.default, add allow rules or replace a rule from the user
+// layer. Such settings are skipped
+// and listed in Config.Ignored.
+//
+// Missing layer files are skipped.
+func LoadLayers(layers []Layer) (*Config, error) {
+	var merged File
+	sources := make(map[string]Source)
+	var ignored []Ignored
+	for _, layer := range layers {
+		data, err := os.ReadFile(layer.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		file, err := Parse(layer.Path, data)
+		if err != nil {
+			return nil, err
+		}
+		// Validate each layer on its own so errors name the right file.
+		if _, err := Compile(file); err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.Path, err)
+		}
+		ignored = append(ignored, merged.merge(file, Source{Layer: layer.Name, Path: layer.Path}, sources)...)
+	}
+
+	merged.applyEnv(sources)
+
+	c, err := Compile(merged)
+	if err != nil {
+		return nil, err
+	}
+	c.Sources = sources
+	c.Ignored = ignored
+	return c, nil
+}
+
+// Ignored is a setting of the project or local layer that would have
+// loosened the configuration.
+type Ignored struct {
+	Value
+	Reason string `json:"reason"`
+}
+
+// strictness orders policy defaults; no default leaves the decision to the
+// handlers and the host.
+var strictness = map[types.PermissionDecision]int{
+	types.PermissionAllow: 0,
+	"":                    1,
+	types.PermissionAsk:   2,
+	types.PermissionDeny:  3,
+}
+
+// restricted reports whether a layer may only tighten the configuration.
+func restricted(layer string) bool {
+	return layer == LayerProject || layer == LayerLocal
+}
+
+// merge overlays src on f, recording the source of each value it sets and
+// returning the settings it skipped.
+func (f *File) merge(src File, source Source, sources map[string]Source) []Ignored {
+	var ignored []Ignored
+	restrict := restricted(source.Layer)
+	skip := func(key, value, reason string) {
+		ignored = append(ignored, Ignored{Value{key, value, source}, reason})
+	}
+	set := func(dst *string, value, key string) {
+		if value != "" {
+			*dst = value
+			sources[key] = source
+		}
+	}
+	// A short timeout or a different executor would let the project turn
+	// denies into failed, non-blocking invocations.
+	if restrict && src.Execution != "" {
+		skip("execution", src.Execution, "execution cannot be set here")
+	} else {
+		set(&f.Execution, src.Execution, "execution")
+	}
+	if restrict && src.Resolution != "" && src.Resolution != handler.ResolutionModeBlockAny.String() {
+		skip("resolution", src.Resolution, "only block_any can be set here")
+	} else {
+		set(&f.Resolution, src.Resolution, "resolution")
+	}
+	if restrict && src.Timeout != "" {
+		skip("timeout", src.Timeout, "timeout cannot be set here")
+	} else {
+		set(&f.Timeout, src.Timeout, "timeout")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(src.Handlers)) {
+		enabled := src.Handlers[name]
+		if restrict && !enabled {
+			skip("handlers."+name, "false", "handlers cannot be disabled here")
+			continue
+		}
+		if f.Handlers == nil {
+			f.Handlers = make(map[string]bool)
+		}
+		f.Handlers[name] = enabled
+		sources["handlers."+name] = source
+	}
+
+	if src.Policy == nil {
+		return ignored
+	}
+	if f.Policy == nil {
+		f.Policy = &policy.File{}
+	}
+	if src.Policy.Default != "" {
+		if restrict && src.Policy.Default == types.PermissionAllow && f.Policy.Default == "" {
+			skip("policy.default", string(src.Policy.Default), "allow cannot be the default here")
+		} else if restrict && strictness[src.Policy.Default] < strictness[f.Policy.Default] {
+			skip("policy.default", string(src.Policy.Default), "looser than "+string(f.Policy.Default)+" from "+sources["policy.default"].String())
+		} else {
+			f.Policy.Default = src.Policy.Default
+			sources["policy.default"] = source
+		}
+	}
+	for _, rule := range src.Policy.Rules {
+		index := -1
+		if rule.Name != "" {
+			index = slices.IndexFunc(f.Policy.Rules, func(r policy.Rule) bool {
+				return r.Name == rule.Name
+			})
+		}
+		switch {
+		case restrict && rule.Decision == types.PermissionAllow:
+			skip(ruleKey(rule, len(f.Policy.Rules)), string(rule.Decision), "allow rules cannot be added here")
+			continue
+		case index < 0:
+			index = len(f.Policy.Rules)
+			f.Policy.Rules = append(f.Policy.Rules, rule)
+		case restrict && !restricted(sources[ruleKey(rule, index)].Layer):
+			skip(ruleKey(rule, index), string(rule.Decision), "replaces the rule from "+sources[ruleKey(rule, index)].String())
+			continue
+		default:
+			f.Policy.Rules[index] = rule
+		}
+		sources[ruleKey(rule, index)] = source
+	}
+	return ignored
+}
+
+// ruleKey names a rule by its name, or by its position as policy errors do.
+func ruleKey(rule policy.Rule, index int) string {
+	if rule.Name != "" {
+		return "policy.rules." + rule.Name
+	}
+	return fmt.Sprintf("policy.rules.#%d", index+1)
+}
+
+// Value is one setting of the effective configuration.
+type Value struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+}
+
+// Values lists the settings of c in file order, each with its source.
+// Handler settings are sorted by name and policy rules show their decision.
+func (c *Config) Values() []Value {
+	var values []Value
+	add := func(key, value string) {
+		if value != "" {
+			values = append(values, Value{key, value, c.Sources[key]})
+		}
+	}
+	add("execution", c.Execution)
+	add("resolution", c.Resolution)
+	add("timeout", c.Timeout)
+	names := make([]string, 0, len(c.Handlers))
+	for name := range c.Handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("handlers."+name, strconv.FormatBool(c.Handlers[name]))
+	}
+	if c.Policy != nil {
+		add("policy.default", string(c.Policy.Default))
+		for i, rule := range c.Policy.Rules {
+			add(ruleKey(rule, i), string(rule.Decision))
+		}
+	}
+	return values
+}
@@ -0,0 +1,252 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/daemon"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/logging"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DefaultInterval is how often Watch checks the files for changes.
+const DefaultInterval = 2 * time.Second
+
+// Loader loads the configuration of each project hooks run in and keeps
+// the last one that validated. Projects are told apart by the cwd of each
+// event, so one daemon serves several projects with their own settings.
+//
+//	loader := config.NewLoader("")
+//	router := handler.NewRouter().
+//	    On(types.EventPreToolUse, loader.Policy(), &SecurityHandler{})
+//	daemon.New(loader.Router(router)).OnServe(loader.Watch).Run()
+type Loader struct {
+	path     string
+	policy   *Policy
+	interval time.Duration
+	onError  func(error)
+
+	mu       sync.Mutex
+	projects map[string]*project
+}
+
+// project is the configuration of one project root.
+type project struct {
+	root    string
+	config  *Config
+	stamps  []layerStamp
+	pending []layerStamp
+	routers map[*handler.Router]*handler.Router
+}
+
+type layerStamp struct {
+	Layer
+	fileStamp
+}
+
+// NewLoader returns a loader for the single file path. When path is empty
+// the loader merges the layers returned by Layers for each project.
+func NewLoader(path string) *Loader {
+	l := &Loader{
+		path:     path,
+		interval: DefaultInterval,
+		onError: func(err error) {
+			logging.Default().Error("config rejected", "error", err)
+		},
+		projects: make(map[string]*project),
+	}
+	l.policy = &Policy{loader: l}
+	return l
+}
+
+func (l *Loader) WithInterval(interval time.Duration) *Loader {
+	l.interval = interval
+	return l
+}
+
+// WithErrorHandler sets the function told about rejected configurations.
+// The default logs them through logging.Default().
+func (l *Loader) WithErrorHandler(fn func(error)) *Loader {
+	l.onError = fn
+	return l
+}
+
+// Path returns the file given to NewLoader, or "" if the loader merges
+// layers.
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// Layers returns the files configuring hooks run in cwd.
+func (l *Loader) Layers(cwd string) []Layer {
+	if l.path != "" {
+		return []Layer{{LayerFile, l.path}}
+	}
+	return Layers(cwd)
+}
+
+// Policy returns the handler evaluating the policy rules configured for
+// the project of each event.
+func (l *Loader) Policy() *Policy {
+	return l.policy
+}
+
+// ConfigFor returns the configuration of the project containing cwd,
+// loading it on first use. An empty cwd means the working directory.
+func (l *Loader) ConfigFor(cwd string) *Config {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.project(cwd).config
+}
+
+// Load reads the configuration of the project containing cwd again. A
+// configuration that validates replaces the project's current one;
+// otherwise the error is returned and the previous configuration stays.
+func (l *Loader) Load(cwd string) (*Config, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	p := l.project(cwd)
+	if err := l.load(p); err != nil {
+		return nil, err
+	}
+	return p.config, nil
+}
+
+// Router returns a router that runs each event through a copy of base
+// configured for the event's project. Configurations that do not validate
+// are reported once and the project keeps its previous one, or none.
+func (l *Loader) Router(base *handler.Router) *handler.Router {
+	return base.Clone().WithSelector(func(input types.HookInput) *handler.Router {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		p := l.project(input.GetCWD())
+		r, ok := p.routers[base]
+		if !ok {
+			r = p.config.Apply(base)
+			p.routers[base] = r
+		}
+		return r
+	})
+}
+
+// Watch is a daemon.OnServe callback that reloads the configuration of
+// every project seen so far when one of its files changes. Routers built
+// by Router pick up the new configuration on the next event.
+func (l *Loader) Watch(s *daemon.Server) {
+	if l.interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.Done():
+				return
+			case <-ticker.C:
+			}
+			l.reload()
+		}
+	}()
+}
+
+func (l *Loader) reload() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range l.projects {
+		if !l.changed(p) {
+			continue
+		}
+		if err := l.load(p); err != nil {
+			l.onError(err)
+			continue
+		}
+		logging.Default().Info("config reloaded", "project", p.root)
+	}
+}
+
+// project returns the entry of the project containing cwd, loading it if
+// it is new. l.mu must be held.
+func (l *Loader) project(cwd string) *project {
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+	root := ""
+	if l.path == "" {
+		root = ProjectRoot(cwd)
+	}
+	p, ok := l.projects[root]
+	if !ok {
+		p = &project{
+			root:    root,
+			config:  &Config{},
+			routers: make(map[*handler.Router]*handler.Router),
+		}
+		l.projects[root] = p
+		if err := l.load(p); err != nil {
+			l.onError(err)
+		}
+	}
+	return p
+}
+
+// load reads the layers of p. l.mu must be held.
+func (l *Loader) load(p *project) error {
+	layers := l.Layers(p.root)
+	// Remember the failed version too, so it is not reported again until
+	// a file changes.
+	p.stamps = stampLayers(layers)
+	c, err := LoadLayers(layers)
+	if err != nil {
+		return err
+	}
+	for _, ig := range c.Ignored {
+		logging.Default().Warn("config setting ignored", "key", ig.Key, "value", ig.Value.Value, "source", ig.Source.String(), "reason", ig.Reason)
+	}
+	p.config = c
+	p.routers = make(map[*handler.Router]*handler.Router)
+	return nil
+}
+
+// changed reports a change once the files have looked the same for one
+// interval, so that a file caught halfway through being written is not
+// loaded.
+func (l *Loader) changed(p *project) bool {
+	stamps := stampLayers(l.Layers(p.root))
+	if slices.Equal(stamps, p.stamps) {
+		return false
+	}
+	if !slices.Equal(stamps, p.pending) {
+		p.pending = stamps
+		return false
+	}
+	return true
+}
+
+func stampLayers(layers []Layer) []layerStamp {
+	stamps := make([]layerStamp, len(layers))
+	for i, layer := range layers {
+		stamps[i] = layerStamp{layer, stat(layer.Path)}
+	}
+	return stamps
+}
+
+type fileStamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func stat(path string) fileStamp {
+	if path == "" {
+		return fileStamp{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/policy"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Policy evaluates the policy rules configured for the project of each
+// event. Register it on the Router where the rules should run; for
+// projects without a policy section it makes no decision.
+type Policy struct {
+	loader *Loader
+}
+
+var noPolicy, _ = policy.Compile(policy.File{})
+
+func (p *Policy) engine(input types.HookInput) *policy.Engine {
+	if engine := p.loader.ConfigFor(input.GetCWD()).policy; engine != nil {
+		return engine
+	}
+	return noPolicy
+}
+
+func (p *Policy) HandlerName() string {
+	return "config.Policy"
+}
+
+func (p *Policy) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	return p.engine(input).HandleEvent(input, eventName)
+}
+
+func (p *Policy) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
+	return p.engine(input).HandlePreToolUse(input)
+}
@@ -0,0 +1,113 @@
+// Package correlation matches each PostToolUse invocation with the
+// PreToolUse invocation that let the tool run, so PostToolUse handlers can
+// see when the tool started, what the PreToolUse decision was and any data
+// the PreToolUse handlers stashed.
+//
+// The two invocations run in separate processes and are joined through the
+// session state, keyed by the tool_use_id when the host provides one and by
+// a fingerprint of the tool name and input otherwise. Identical calls in
+// flight at the same time are matched first in, first out.
+package correlation
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/callkey"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const (
+	keyPrefix   = "correlation/"
+	stashPrefix = "correlation/stash/"
+)
+
+// Record is what a PreToolUse invocation leaves for the matching PostToolUse
+// invocation. Decision is empty when no PreToolUse handler expressed an
+// opinion.
+type Record struct {
+	ToolName    types.ToolName             `json:"tool_name"`
+	ToolUseID   string                     `json:"tool_use_id,omitempty"`
+	Fingerprint string                     `json:"fingerprint"`
+	StartedAt   time.Time                  `json:"started_at"`
+	PreDuration time.Duration              `json:"pre_duration"`
+	Decision    types.PermissionDecision   `json:"decision,omitempty"`
+	Reason      string                     `json:"reason,omitempty"`
+	Error       string                     `json:"error,omitempty"`
+	Data        map[string]json.RawMessage `json:"data,omitempty"`
+}
+
+// Span is a tool call seen from PostToolUse: the PreToolUse record plus the
+// time the tool finished.
+type Span struct {
+	Record
+	EndedAt time.Time
+}
+
+// Duration returns the time between the end of the PreToolUse invocation
+// and the PostToolUse invocation, which approximates the tool's run time.
+func (s Span) Duration() time.Duration {
+	return s.EndedAt.Sub(s.StartedAt)
+}
+
+// Get decodes the stashed value name into dst. Span implements state.Getter,
+// so state.Lookup works on it.
+func (s Span) Get(name string, dst any) (bool, error) {
+	raw, ok := s.Data[name]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return true, fmt.Errorf("failed to decode stashed %q: %w", name, err)
+	}
+	return true, nil
+}
+
+// Fingerprint returns a stable hash of a tool call. Map keys are encoded in
+// sorted order, so equal inputs always produce the same fingerprint.
+func Fingerprint(toolName types.ToolName, toolInput map[string]interface{}) string {
+	return callkey.Fingerprint(string(toolName), toolInput)
+}
+
+func callKey(toolName types.ToolName, toolUseID string, toolInput map[string]interface{}) string {
+	return callkey.Key(string(toolName), toolUseID, toolInput)
+}
+
+// Stash saves a value under name for the PostToolUse invocation of the same
+// tool call, where it is available from Span.Get. Call it from a PreToolUse
+// handler.
+func Stash(input types.PreToolUseInput, name string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode stashed %q: %w", name, err)
+	}
+	key := stashPrefix + callKey(input.ToolName, input.ToolUseID, input.ToolInput)
+	return state.Update(input.Context(), func(tx *state.Tx) error {
+		stash, _, err := state.Lookup[map[string]json.RawMessage](tx, key)
+		if err != nil {
+			return err
+		}
+		if stash == nil {
+			stash = make(map[string]json.RawMessage)
+		}
+		stash[name] = data
+		return tx.SetWithTTL(key, stash, DefaultTTL)
+	})
+}
+
+// Lookup returns the span of the tool call that input reports on. It
+// reports false if no PreToolUse record exists, for example because the
+// router that handled PreToolUse had no Tracker.
+func Lookup(input types.PostToolUseInput) (Span, bool, error) {
+	session := state.FromContext(input.Context())
+	if session == nil {
+		return Span{}, false, state.ErrNoSession
+	}
+	records, found, err := state.Lookup[[]Record](session, keyPrefix+callKey(input.ToolName, input.ToolUseID, input.ToolInput))
+	if err != nil || !found || len(records) == 0 {
+		return Span{}, false, err
+	}
+	return Span{Record: records[0], EndedAt: time.Now()}, true, nil
+}
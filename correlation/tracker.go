@@ -0,0 +1,138 @@
+package correlation
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/tracing"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DefaultTTL bounds how long a record waits for its PostToolUse invocation,
+// which never comes for tools the user rejects or that are interrupted.
+const DefaultTTL = time.Hour
+
+// Tracker is a handler.Observer that writes a Record after each PreToolUse
+// invocation and removes it after the matching PostToolUse invocation.
+// Register it on the routers handling both events:
+//
+//	router.WithObserver(correlation.NewTracker())
+//
+// Tracking is best effort: state errors are ignored so that they never
+// affect the hook's decision.
+type Tracker struct {
+	ttl time.Duration
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{ttl: DefaultTTL}
+}
+
+func (t *Tracker) WithTTL(ttl time.Duration) *Tracker {
+	t.ttl = ttl
+	return t
+}
+
+func (t *Tracker) Observe(inv handler.Invocation) {
+	switch in := inv.Input.(type) {
+	case types.PreToolUseInput:
+		t.start(in, inv)
+	case types.PostToolUseInput:
+		t.finish(in)
+	}
+}
+
+func (t *Tracker) start(input types.PreToolUseInput, inv handler.Invocation) {
+	key := callKey(input.ToolName, input.ToolUseID, input.ToolInput)
+	record := Record{
+		ToolName:    input.ToolName,
+		ToolUseID:   input.ToolUseID,
+		Fingerprint: Fingerprint(input.ToolName, input.ToolInput),
+		StartedAt:   inv.Start.Add(inv.Duration),
+		PreDuration: inv.Duration,
+	}
+	if inv.Err != nil {
+		record.Error = inv.Err.Error()
+	} else {
+		record.Decision, record.Reason = decisionOf(inv.Output)
+	}
+
+	_ = state.Update(input.Context(), func(tx *state.Tx) error {
+		stash, _, _ := state.Lookup[map[string]json.RawMessage](tx, stashPrefix+key)
+		tx.Delete(stashPrefix + key)
+		if record.Decision == types.PermissionDeny {
+			return nil
+		}
+		record.Data = stash
+
+		records, _, _ := state.Lookup[[]Record](tx, keyPrefix+key)
+		return tx.SetWithTTL(keyPrefix+key, append(records, record), t.ttl)
+	})
+}
+
+func (t *Tracker) finish(input types.PostToolUseInput) {
+	call := callKey(input.ToolName, input.ToolUseID, input.ToolInput)
+	key := keyPrefix + call
+	_ = state.Update(input.Context(), func(tx *state.Tx) error {
+		records, found, _ := state.Lookup[[]Record](tx, key)
+		if !found {
+			return nil
+		}
+		traceToolCall(input, call, records[0])
+		if len(records) <= 1 {
+			tx.Delete(key)
+			return nil
+		}
+		return tx.SetWithTTL(key, records[1:], t.ttl)
+	})
+}
+
+// traceToolCall emits the span that the PreToolUse and PostToolUse
+// invocation spans of the call are children of, from the start of the
+// PreToolUse invocation to now.
+func traceToolCall(input types.PostToolUseInput, call string, record Record) {
+	ctx := input.Context()
+	if !tracing.Enabled(ctx) {
+		return
+	}
+	ctx = tracing.WithParent(ctx, tracing.SessionTraceID(input.SessionID), tracing.SpanID{})
+	_, span := tracing.StartWithID(ctx, "tool_call", tracing.ToolCallSpanID(input.SessionID, call))
+	span.SetStart(record.StartedAt.Add(-record.PreDuration))
+	span.SetAttributes(
+		tracing.Attribute{Key: "tool.name", Value: string(record.ToolName)},
+		tracing.Attribute{Key: "tool.fingerprint", Value: record.Fingerprint},
+		tracing.Attribute{Key: "hook.decision", Value: string(record.Decision)},
+	)
+	if record.ToolUseID != "" {
+		span.SetAttribute("tool.use_id", record.ToolUseID)
+	}
+	span.End()
+}
+
+// decisionOf extracts the permission decision of a resolved PreToolUse
+// output. A generic blocking output counts as a denial.
+func decisionOf(output types.HookOutput) (types.PermissionDecision, string) {
+	switch out := output.(type) {
+	case types.PreToolUseOutput:
+		if decision := out.Decision(); decision != "" {
+			return decision, out.Reason()
+		}
+		if out.ExitWith() == types.ExitBlocking {
+			return types.PermissionDeny, stopReason(out.BaseOutput)
+		}
+	case types.BaseOutput:
+		if out.ExitWith() == types.ExitBlocking {
+			return types.PermissionDeny, stopReason(out)
+		}
+	}
+	return "", ""
+}
+
+func stopReason(o types.BaseOutput) string {
+	if o.StopReason != nil {
+		return *o.StopReason
+	}
+	return ""
+}
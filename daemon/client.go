@@ -0,0 +1,123 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// ErrNotHandled is wrapped by the errors of Forward raised before the
+// server handled the event: no trusted server was reachable, or it declined
+// the request. Handling the event in-process is safe after those, whereas
+// after other errors the server may already have run the handlers.
+var ErrNotHandled = errors.New("event not handled by the hook daemon")
+
+// Forward sends input to the server and returns its response.
+func (d *Daemon) Forward(input []byte) (Response, error) {
+	return d.call(request{Op: opHandle, Input: input})
+}
+
+// Ping reports whether a server for this build is running.
+func (d *Daemon) Ping() error {
+	_, err := d.call(request{Op: opPing})
+	return err
+}
+
+// Stop asks the running server to shut down.
+func (d *Daemon) Stop() error {
+	_, err := d.call(request{Op: opShutdown})
+	return err
+}
+
+func (d *Daemon) call(req request) (Response, error) {
+	// Only a server of the same user may decide; the directory check keeps
+	// others from replacing the socket after the socket check.
+	if err := checkOwned(filepath.Dir(d.socket), true); err != nil {
+		return Response{}, fmt.Errorf("%w: %v", ErrNotHandled, err)
+	}
+	if err := checkOwned(d.socket, false); err != nil {
+		return Response{}, fmt.Errorf("%w: %v", ErrNotHandled, err)
+	}
+	conn, err := net.DialTimeout("unix", d.socket, d.dialTimeout)
+	if err != nil {
+		return Response{}, fmt.Errorf("%w: %v", ErrNotHandled, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(d.requestTimeout))
+
+	req.Version = d.version
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		// The server rejects an incomplete request.
+		return Response{}, fmt.Errorf("%w: %v", ErrNotHandled, err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, err
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("%w: %s", ErrNotHandled, resp.Error)
+	}
+	return resp, nil
+}
+
+func (d *Daemon) runClient() {
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Hook execution failed: failed to read input: %v\n", err)
+		types.Exit(1)
+	}
+
+	resp, err := d.Forward(input)
+	if err == nil {
+		os.Stdout.WriteString(resp.Stdout)
+		os.Stderr.WriteString(resp.Stderr)
+		types.Exit(resp.ExitCode)
+	}
+	if !errors.Is(err, ErrNotHandled) {
+		// The server may have run the handlers already; running them again
+		// would repeat their side effects.
+		fmt.Fprintf(os.Stderr, "Hook execution failed: hook daemon: %v\n", err)
+		types.Exit(1)
+	}
+
+	if d.autoStart {
+		d.spawn()
+	}
+	output, err := d.router.Process(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Hook execution failed: %v\n", err)
+		types.Exit(1)
+	}
+	types.OutputAndExit(output)
+}
+
+// spawn starts a server in the background from the same executable. Errors
+// are ignored: the next invocation simply tries again.
+func (d *Daemon) spawn() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe)
+	cmd.Env = append(os.Environ(), ModeEnv+"="+ModeServe, SocketEnv+"="+d.socket)
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	cmd.Process.Release()
+}
+
+// Execute runs router through a daemon with default settings. It can
+// replace handler.Execute in a hook's main function.
+func Execute(router *handler.Router) {
+	New(router).Run()
+}
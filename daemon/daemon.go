@@ -0,0 +1,174 @@
+// Package daemon runs a Router as a long-lived server on a Unix domain
+// socket, with the same binary acting as a thin client.
+//
+// Hosts launch a hook binary for every event, so configuration, policies
+// and caches are normally rebuilt each time. With daemon.New(router).Run()
+// in place of handler.Execute(router), the first invocation handles the
+// event in-process and starts a background server; later invocations
+// forward stdin to the server and relay its stdout and exit code. If the
+// server is not reachable the client falls back to handling the event
+// itself, so the hook never depends on the daemon being up. The server
+// exits after a period without requests, and whenever it finds that the
+// binary was rebuilt.
+//
+// The server keeps the environment it was started with; variables set on
+// later invocations are not seen by handlers.
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// ModeEnv selects how Run behaves: "serve" runs the server in the
+// foreground, "off" always handles events in-process, "stop" asks a running
+// server to shut down. Anything else runs the client.
+const ModeEnv = "CLAUDE_HOOKS_DAEMON"
+
+// SocketEnv overrides the socket path.
+const SocketEnv = "CLAUDE_HOOKS_DAEMON_SOCKET"
+
+const (
+	ModeServe = "serve"
+	ModeOff   = "off"
+	ModeStop  = "stop"
+)
+
+const (
+	DefaultIdleTimeout    = 10 * time.Minute
+	DefaultDialTimeout    = 200 * time.Millisecond
+	DefaultRequestTimeout = 60 * time.Second
+)
+
+type Daemon struct {
+	router         *handler.Router
+	socket         string
+	idleTimeout    time.Duration
+	dialTimeout    time.Duration
+	requestTimeout time.Duration
+	autoStart      bool
+	version        string
+	onServe        []func(*Server)
+}
+
+func New(router *handler.Router) *Daemon {
+	return &Daemon{
+		router:         router,
+		socket:         DefaultSocket(),
+		idleTimeout:    DefaultIdleTimeout,
+		dialTimeout:    DefaultDialTimeout,
+		requestTimeout: DefaultRequestTimeout,
+		autoStart:      true,
+		version:        executableVersion(),
+	}
+}
+
+func (d *Daemon) WithSocket(path string) *Daemon {
+	d.socket = path
+	return d
+}
+
+// WithIdleTimeout sets how long the server waits for a request before
+// exiting. Zero keeps it running until stopped.
+func (d *Daemon) WithIdleTimeout(timeout time.Duration) *Daemon {
+	d.idleTimeout = timeout
+	return d
+}
+
+// WithDialTimeout bounds how long the client waits to connect before
+// falling back to in-process handling.
+func (d *Daemon) WithDialTimeout(timeout time.Duration) *Daemon {
+	d.dialTimeout = timeout
+	return d
+}
+
+func (d *Daemon) WithRequestTimeout(timeout time.Duration) *Daemon {
+	d.requestTimeout = timeout
+	return d
+}
+
+// WithAutoStart controls whether a client that finds no server starts one
+// in the background. It is on by default.
+func (d *Daemon) WithAutoStart(enabled bool) *Daemon {
+	d.autoStart = enabled
+	return d
+}
+
+// OnServe registers fn to run when the server starts, before it accepts
+// connections, for example to start watching configuration files.
+func (d *Daemon) OnServe(fn func(*Server)) *Daemon {
+	d.onServe = append(d.onServe, fn)
+	return d
+}
+
+func (d *Daemon) Socket() string {
+	return d.socket
+}
+
+// Run is the main function of a hook binary using the daemon. It does not
+// return.
+func (d *Daemon) Run() {
+	if os.Getenv(handler.ManifestEnv) != "" {
+		handler.Execute(d.router)
+	}
+	switch os.Getenv(ModeEnv) {
+	case ModeServe:
+		if err := d.Serve(); err != nil {
+			fmt.Fprintf(os.Stderr, "hook daemon: %v\n", err)
+			types.Exit(1)
+		}
+		types.Exit(0)
+	case ModeStop:
+		if err := d.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "hook daemon: %v\n", err)
+			types.Exit(1)
+		}
+		types.Exit(0)
+	case ModeOff:
+		handler.Execute(d.router)
+	default:
+		d.runClient()
+	}
+}
+
+// DefaultSocket returns $CLAUDE_HOOKS_DAEMON_SOCKET, or a per-binary socket
+// in a private directory under $XDG_RUNTIME_DIR or the temp directory.
+func DefaultSocket() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("claude-hooks-%d", os.Getuid()))
+	} else {
+		dir = filepath.Join(dir, "claude-hooks")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	sum := sha256.Sum256([]byte(exe))
+	return filepath.Join(dir, filepath.Base(exe)+"-"+hex.EncodeToString(sum[:4])+".sock")
+}
+
+// executableVersion identifies the build of the running binary, so that a
+// server left over from a previous build is not used.
+func executableVersion() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(exe)
+	if err != nil {
+		return exe
+	}
+	return fmt.Sprintf("%s:%d:%d", exe, info.Size(), info.ModTime().UnixNano())
+}
@@ -0,0 +1,5 @@
+//go:build !unix
+
+package daemon
+
+func checkOwned(path string, dir bool) error { return nil }
@@ -0,0 +1,29 @@
+//go:build unix
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkOwned refuses path unless it is owned by the current user and is not
+// a symlink. A directory must also be closed to other users, so that nobody
+// else can put a socket in it.
+func checkOwned(path string, dir bool) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return fmt.Errorf("%s is a symlink", path)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by uid %d, not %d", path, st.Uid, os.Getuid())
+	}
+	if dir && info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s is accessible to other users (mode %o); it must be 0700", path, info.Mode().Perm())
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package daemon
+
+import "encoding/json"
+
+// Operations of a request.
+const (
+	opHandle   = "handle"
+	opPing     = "ping"
+	opShutdown = "shutdown"
+)
+
+// request is sent by the client, one per connection.
+type request struct {
+	Op      string          `json:"op"`
+	Version string          `json:"version"`
+	Input   json.RawMessage `json:"input,omitempty"`
+}
+
+// Response is what the hook process would have written and exited with.
+type Response struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr,omitempty"`
+	ExitCode int    `json:"exit_code"`
+	// Error reports that the server did not handle the request, in which
+	// case the client handles it in-process.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,210 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/filelock"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+var ErrRunning = errors.New("a daemon is already listening on the socket")
+
+// Server is a running daemon.
+type Server struct {
+	daemon   *Daemon
+	router   atomic.Pointer[handler.Router]
+	listener net.Listener
+
+	mu     sync.Mutex
+	active int
+	idle   *time.Timer
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// Router returns the router handling requests.
+func (s *Server) Router() *handler.Router {
+	return s.router.Load()
+}
+
+// SetRouter replaces the router for subsequent requests. Requests in flight
+// finish with the previous one.
+func (s *Server) SetRouter(router *handler.Router) {
+	s.router.Store(router)
+}
+
+// Done is closed when the server shuts down.
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Shutdown stops accepting connections. Serve returns once requests in
+// flight have been answered.
+func (s *Server) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	s.listener.Close()
+}
+
+// Serve listens on the socket and handles requests until the idle timeout
+// expires or a client asks it to stop.
+func (d *Daemon) Serve() error {
+	listener, err := d.listen()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(d.socket)
+
+	s := &Server{daemon: d, listener: listener, done: make(chan struct{})}
+	s.router.Store(d.router)
+	if d.idleTimeout > 0 {
+		s.idle = time.AfterFunc(d.idleTimeout, s.Shutdown)
+	}
+	for _, fn := range d.onServe {
+		fn(s)
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				break
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		s.begin()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer s.end()
+			s.serveConn(conn)
+		}()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// listen binds the socket, replacing a stale socket file but refusing to
+// take over from a live server. The check and bind happen under a lock so
+// that clients starting servers concurrently end up with exactly one.
+func (d *Daemon) listen() (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(d.socket), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := checkOwned(filepath.Dir(d.socket), true); err != nil {
+		return nil, fmt.Errorf("refusing socket directory: %w", err)
+	}
+	lock, err := filelock.Acquire(context.Background(), d.socket+".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Release()
+
+	if conn, err := net.DialTimeout("unix", d.socket, d.dialTimeout); err == nil {
+		conn.Close()
+		return nil, ErrRunning
+	}
+	if err := os.Remove(d.socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	listener, err := net.Listen("unix", d.socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", d.socket, err)
+	}
+	if err := os.Chmod(d.socket, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+func (s *Server) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active++
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+}
+
+func (s *Server) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	if s.active == 0 && s.idle != nil && !s.closed {
+		s.idle.Reset(s.daemon.idleTimeout)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.daemon.requestTimeout))
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		json.NewEncoder(conn).Encode(Response{Error: fmt.Sprintf("bad request: %v", err)})
+		return
+	}
+
+	var resp Response
+	switch {
+	case req.Version != s.daemon.version:
+		// The binary was rebuilt; let the client handle the event and make
+		// way for a server running the new build.
+		resp.Error = "daemon runs a different build"
+		defer s.Shutdown()
+	case req.Op == opPing:
+	case req.Op == opShutdown:
+		defer s.Shutdown()
+	case req.Op == opHandle:
+		resp = s.handle(req.Input)
+	default:
+		resp.Error = fmt.Sprintf("unknown operation %q", req.Op)
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// handle produces what handler.Execute would have written and exited with.
+func (s *Server) handle(input []byte) (resp Response) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = Response{Stderr: fmt.Sprintf("Hook execution failed: panic: %v\n", r), ExitCode: 1}
+		}
+		types.RunExitHooks()
+	}()
+
+	output, err := s.Router().Process(input)
+	if err != nil {
+		return Response{Stderr: fmt.Sprintf("Hook execution failed: %v\n", err), ExitCode: 1}
+	}
+	var stdout bytes.Buffer
+	if err := types.WriteOutput(&stdout, output); err != nil {
+		return Response{Stderr: fmt.Sprintf("Error marshaling output: %v\n", err), ExitCode: 1}
+	}
+	var stderr bytes.Buffer
+	types.WriteFeedback(&stderr, output)
+	return Response{Stdout: stdout.String(), Stderr: stderr.String(), ExitCode: output.ExitWith()}
+}
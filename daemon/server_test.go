@@ -0,0 +1,30 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+type denyAll struct{}
+
+func (denyAll) HandleEvent(types.HookInput, types.EventName) (types.HookOutput, error) {
+	return types.Deny("denied by test"), nil
+}
+
+// TestHandleDenyReason checks that a deny reaches the host as exit code 2
+// with its reason on stderr, which is all the host reads on that code.
+func TestHandleDenyReason(t *testing.T) {
+	t.Setenv("CLAUDE_HOOKS_STATE_DIR", t.TempDir())
+	s := &Server{}
+	s.SetRouter(handler.NewRouter().On(types.EventPreToolUse, denyAll{}))
+
+	resp := s.handle([]byte(`{"hook_event_name":"PreToolUse","session_id":"s","cwd":"/tmp","transcript_path":"/dev/null","tool_name":"Bash","tool_input":{"command":"rm -rf /"}}`))
+	if resp.ExitCode != types.ExitBlocking {
+		t.Errorf("exit code %d, want %d", resp.ExitCode, types.ExitBlocking)
+	}
+	if resp.Stderr != "denied by test\n" {
+		t.Errorf("stderr %q, want %q", resp.Stderr, "denied by test\n")
+	}
+}
@@ -2,96 +2,115 @@ package main
 
 import (
 	"fmt"
-	"log"
-	"strings"
 	"time"
 
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/logging"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/metrics"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/secrets"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/shell"
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
 )
 
 type SecurityHandler struct{}
 
 func (h *SecurityHandler) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
-	log.Printf("[SECURITY] Checking tool: %s", input.ToolName)
+	input.Logger().Debug("checking tool", "handler", "security")
 
-	// Block dangerous bash commands
+	// Block dangerous bash commands. The command is parsed rather than
+	// substring-matched so quoting, pipes and "&&" chains cannot hide an
+	// invocation and words like "format" in arguments do not false-positive.
 	if input.ToolName == types.ToolBash {
 		if cmd, ok := input.ToolInput["command"].(string); ok {
-			dangerous := []string{"rm -rf", "sudo", "chmod 777", "format", "del"}
-			for _, danger := range dangerous {
-				if strings.Contains(strings.ToLower(cmd), danger) {
-					continueVal := false
-					allowTool := false
-					return types.PreToolUseOutput{
-						BaseOutput: types.BaseOutput{
-							Continue:   &continueVal,
-							StopReason: stringPtr(fmt.Sprintf("Dangerous command blocked: %s", danger)),
-						},
-						AllowTool: &allowTool,
-					}, nil
+			script, err := shell.Parse(cmd)
+			if err != nil {
+				return blockTool(fmt.Sprintf("Unparseable command blocked: %v", err)), nil
+			}
+			if removals := script.RecursiveRemoveOutside(input.CWD); len(removals) > 0 {
+				return blockTool(fmt.Sprintf("Recursive delete outside the workspace blocked: %s", removals[0])), nil
+			}
+			if found := script.Invokes("sudo", "mkfs", "dd"); len(found) > 0 {
+				return blockTool(fmt.Sprintf("Dangerous command blocked: %s", found[0])), nil
+			}
+			for _, c := range script.Invokes("chmod") {
+				for _, operand := range c.Resolve().Operands() {
+					if operand.Value == "777" {
+						return blockTool("Dangerous command blocked: chmod 777"), nil
+					}
 				}
 			}
 		}
 	}
 
-	log.Printf("[SECURITY] Tool %s approved", input.ToolName)
+	input.Logger().Info("tool approved", "handler", "security")
 	return types.PreToolUseOutput{}, nil
 }
 
+func blockTool(reason string) types.PreToolUseOutput {
+	continueVal := false
+	allowTool := false
+	return types.PreToolUseOutput{
+		BaseOutput: types.BaseOutput{
+			Continue:   &continueVal,
+			StopReason: &reason,
+		},
+		AllowTool: &allowTool,
+	}
+}
+
 type AuditHandler struct{}
 
 func (h *AuditHandler) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
-	log.Printf("[AUDIT] Tool execution: %s in session %s", input.ToolName, input.SessionID)
+	input.Logger().Info("tool execution", "handler", "audit")
 	return types.PreToolUseOutput{}, nil
 }
 
 func (h *AuditHandler) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
-	log.Printf("[AUDIT] Tool completed: %s", input.ToolName)
+	input.Logger().Info("tool completed", "handler", "audit")
 	return types.PostToolUseOutput{}, nil
 }
 
+// MetricsHandler shows per-handler logic; the counters and latency
+// histograms themselves come from the metrics.Recorder observer.
 type MetricsHandler struct{}
 
 func (h *MetricsHandler) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
-	log.Printf("[METRICS] Incrementing counter for tool: %s", input.ToolName)
+	input.Logger().Debug("incrementing counter", "handler", "metrics")
 	return types.PreToolUseOutput{}, nil
 }
 
 func (h *MetricsHandler) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
-	log.Printf("[METRICS] Recording execution time for tool: %s", input.ToolName)
+	input.Logger().Debug("recording execution time", "handler", "metrics")
 	return types.PostToolUseOutput{}, nil
 }
 
-type ContentFilterHandler struct{}
+type ContentFilterHandler struct {
+	scanner *secrets.Scanner
+}
 
 func (h *ContentFilterHandler) HandleUserPromptSubmit(input types.UserPromptSubmitInput) (types.UserPromptSubmitOutput, error) {
-	log.Printf("[FILTER] Checking prompt content")
-
-	// Block prompts with sensitive content
-	blocked := []string{"password", "secret", "private key", "ssn", "credit card"}
-	prompt := strings.ToLower(input.Prompt)
-
-	for _, word := range blocked {
-		if strings.Contains(prompt, word) {
-			continueVal := false
-			allowSubmit := false
-			return types.UserPromptSubmitOutput{
-				BaseOutput: types.BaseOutput{
-					Continue:   &continueVal,
-					StopReason: stringPtr(fmt.Sprintf("Blocked sensitive content: %s", word)),
-				},
-				AllowSubmit: &allowSubmit,
-			}, nil
-		}
+	input.Logger().Debug("checking prompt content", "handler", "filter")
+
+	// Block prompts that contain credentials. Findings only carry redacted
+	// previews, so the reason is safe to show.
+	if findings := h.scanner.ScanInput(input); len(findings) > 0 {
+		continueVal := false
+		allowSubmit := false
+		return types.UserPromptSubmitOutput{
+			BaseOutput: types.BaseOutput{
+				Continue:   &continueVal,
+				StopReason: stringPtr(secrets.Summarize(findings)),
+			},
+			AllowSubmit: &allowSubmit,
+		}, nil
 	}
 
-	log.Printf("[FILTER] Prompt approved")
+	input.Logger().Info("prompt approved", "handler", "filter")
 	return types.UserPromptSubmitOutput{}, nil
 }
 
 func demonstrateSyncExecution() {
-	log.Println("=== SYNC EXECUTION DEMO ===")
+	logging.Default().Info("sync execution demo")
 
 	router := handler.NewRouter().
 		OnPreToolUse(
@@ -100,14 +119,15 @@ func demonstrateSyncExecution() {
 			&MetricsHandler{},  // Runs third
 		).
 		WithExecution(handler.ExecutionModeSync).
-		WithResolution(handler.ResolutionModeBlockAny)
+		WithResolution(handler.ResolutionModeBlockAny).
+		WithObserver(metrics.FromEnv()) // set CLAUDE_HOOKS_METRICS_FILE to aggregate metrics
 
-	log.Println("Configured sync execution with BlockAny resolution")
+	logging.Default().Debug("configured sync execution with BlockAny resolution")
 	handler.Execute(router)
 }
 
 func demonstrateAsyncExecution() {
-	log.Println("=== ASYNC EXECUTION DEMO ===")
+	logging.Default().Info("async execution demo")
 
 	router := handler.NewRouter().
 		OnPreToolUse(
@@ -121,14 +141,15 @@ func demonstrateAsyncExecution() {
 		).
 		WithExecution(handler.ExecutionModeAsync).
 		WithResolution(handler.ResolutionModeBlockAny).
-		WithTimeout(10 * time.Second)
+		WithTimeout(10 * time.Second).
+		WithObserver(metrics.FromEnv())
 
-	log.Println("Configured async execution with 10s timeout")
+	logging.Default().Debug("configured async execution with 10s timeout")
 	handler.Execute(router)
 }
 
 func demonstrateMixedHandlers() {
-	log.Println("=== MIXED HANDLERS DEMO ===")
+	logging.Default().Info("mixed handlers demo")
 
 	router := handler.NewRouter().
 		OnPreToolUse(
@@ -136,15 +157,16 @@ func demonstrateMixedHandlers() {
 			&AuditHandler{},
 		).
 		OnUserPromptSubmit(
-			&ContentFilterHandler{},
+			&ContentFilterHandler{scanner: secrets.NewScanner()},
 		).
 		OnPostToolUse(
 			&MetricsHandler{},
 		).
 		WithExecution(handler.ExecutionModeSync).
-		WithResolution(handler.ResolutionModeFirstWin)
+		WithResolution(handler.ResolutionModeFirstWin).
+		WithObserver(metrics.FromEnv())
 
-	log.Println("Configured multiple event types with FirstWin resolution")
+	logging.Default().Debug("configured multiple event types with FirstWin resolution")
 	handler.Execute(router)
 }
 
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/policy"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+func main() {
+	path := os.Getenv("HOOK_POLICY_FILE")
+	if path == "" {
+		path = "policy.yaml"
+	}
+
+	engine, err := policy.LoadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load policy: %v\n", err)
+		os.Exit(1)
+	}
+
+	router := handler.NewRouter().
+		On(types.EventPreToolUse, engine).
+		On(types.EventUserPromptSubmit, engine)
+
+	handler.Execute(router)
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"log"
 	"strings"
 
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
@@ -11,24 +10,23 @@ import (
 type ExampleHandler struct{}
 
 func (h *ExampleHandler) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
-	log.Printf("PreToolUse: Tool=%s", input.ToolName)
+	input.Logger().Info("PreToolUse")
 
 	if input.ToolName == types.ToolBash {
-		log.Printf("Bash command detected in session %s", input.SessionID)
+		input.Logger().Info("Bash command detected")
 	}
 
 	return types.PreToolUseOutput{}, nil
 }
 
 func (h *ExampleHandler) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
-	log.Printf("PostToolUse: Tool=%s", input.ToolName)
-	log.Printf("Tool response: %v", input.ToolResponse)
+	input.Logger().Info("PostToolUse", "tool_response", input.ToolResponse)
 
 	return types.PostToolUseOutput{}, nil
 }
 
 func (h *ExampleHandler) HandleUserPromptSubmit(input types.UserPromptSubmitInput) (types.UserPromptSubmitOutput, error) {
-	log.Printf("UserPromptSubmit: %s", input.Prompt)
+	input.Logger().Info("UserPromptSubmit", "prompt", input.Prompt)
 
 	if strings.Contains(strings.ToLower(input.Prompt), "dangerous") {
 		continueVal := false
@@ -46,7 +44,7 @@ func (h *ExampleHandler) HandleUserPromptSubmit(input types.UserPromptSubmitInpu
 }
 
 func (h *ExampleHandler) HandleStop(input types.StopInput) (types.StopOutput, error) {
-	log.Printf("Stop event: StopHookActive=%t", input.StopHookActive)
+	input.Logger().Info("Stop", "stop_hook_active", input.StopHookActive)
 	return types.StopOutput{}, nil
 }
 
@@ -1,3 +1,5 @@
 module github.com/HeroSizy/claude-code-hooks-go-sdk
 
 go 1.24.5
+
+require gopkg.in/yaml.v3 v3.0.1
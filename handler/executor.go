@@ -2,8 +2,10 @@ package handler
 
 import (
 	"context"
-	"sync"
+	"fmt"
+	"time"
 
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/tracing"
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
 )
 
@@ -15,10 +17,33 @@ const (
 	ExecutionModePipeline
 )
 
+func (m ExecutionMode) String() string {
+	switch m {
+	case ExecutionModeSync:
+		return "sync"
+	case ExecutionModeAsync:
+		return "async"
+	case ExecutionModePipeline:
+		return "pipeline"
+	}
+	return "unknown"
+}
+
+// ParseExecutionMode parses the names returned by String.
+func ParseExecutionMode(s string) (ExecutionMode, error) {
+	for _, m := range []ExecutionMode{ExecutionModeSync, ExecutionModeAsync, ExecutionModePipeline} {
+		if s == m.String() {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown execution mode %q (want sync, async or pipeline)", s)
+}
+
 type HandlerResult struct {
-	Output types.HookOutput
-	Error  error
-	Index  int
+	Output   types.HookOutput
+	Error    error
+	Index    int
+	Duration time.Duration
 }
 
 type Executor interface {
@@ -37,21 +62,16 @@ func (e *SyncExecutor) Execute(ctx context.Context, input types.HookInput, event
 		default:
 		}
 
-		output, err := handler.HandleEvent(input, eventName)
-		result := HandlerResult{
-			Output: output,
-			Error:  err,
-			Index:  i,
-		}
+		result := runHandler(ctx, handler, input, eventName, i)
 		results = append(results, result)
 
 		// Stop on first error in sync mode
-		if err != nil {
-			return results, err
+		if result.Error != nil {
+			return results, result.Error
 		}
 
 		// Check if this handler blocked the operation
-		if output != nil && isBlocking(output) {
+		if result.Output != nil && isBlocking(result.Output) {
 			return results, nil
 		}
 	}
@@ -61,40 +81,40 @@ func (e *SyncExecutor) Execute(ctx context.Context, input types.HookInput, event
 
 type AsyncExecutor struct{}
 
+// Execute runs the handlers concurrently. When ctx ends first, the handlers
+// still running get a result carrying ctx's error; they keep running, but
+// what they return later is dropped, so the results are never written after
+// Execute returns.
 func (e *AsyncExecutor) Execute(ctx context.Context, input types.HookInput, eventName types.EventName, handlers []Handler) ([]HandlerResult, error) {
 	if len(handlers) == 0 {
 		return nil, nil
 	}
 
-	results := make([]HandlerResult, len(handlers))
-	var wg sync.WaitGroup
-
+	start := time.Now()
+	finished := make(chan HandlerResult, len(handlers))
 	for i, handler := range handlers {
-		wg.Add(1)
 		go func(index int, h Handler) {
-			defer wg.Done()
-
-			output, err := h.HandleEvent(input, eventName)
-			results[index] = HandlerResult{
-				Output: output,
-				Error:  err,
-				Index:  index,
-			}
+			finished <- runHandler(ctx, h, input, eventName, index)
 		}(i, handler)
 	}
 
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-ctx.Done():
-		return results, ctx.Err()
-	case <-done:
-		return results, nil
+	results := make([]HandlerResult, len(handlers))
+	received := make([]bool, len(handlers))
+	for range handlers {
+		select {
+		case result := <-finished:
+			results[result.Index] = result
+			received[result.Index] = true
+		case <-ctx.Done():
+			for i := range results {
+				if !received[i] {
+					results[i] = HandlerResult{Error: ctx.Err(), Index: i, Duration: time.Since(start)}
+				}
+			}
+			return results, ctx.Err()
+		}
 	}
+	return results, nil
 }
 
 type PipelineExecutor struct{}
@@ -114,22 +134,17 @@ func (e *PipelineExecutor) Execute(ctx context.Context, input types.HookInput, e
 		default:
 		}
 
-		output, err := handler.HandleEvent(currentInput, eventName)
-		result := HandlerResult{
-			Output: output,
-			Error:  err,
-			Index:  i,
-		}
+		result := runHandler(ctx, handler, currentInput, eventName, i)
 		results = append(results, result)
 
-		if err != nil {
-			return results, err
+		if result.Error != nil {
+			return results, result.Error
 		}
 
 		// In pipeline mode, we don't chain outputs to inputs since
 		// each event type has specific input/output types
 		// Pipeline is mainly useful for middleware-style processing
-		if output != nil && isBlocking(output) {
+		if result.Output != nil && isBlocking(result.Output) {
 			return results, nil
 		}
 	}
@@ -137,6 +152,33 @@ func (e *PipelineExecutor) Execute(ctx context.Context, input types.HookInput, e
 	return results, nil
 }
 
+// runHandler calls h and times it. With tracing enabled the call gets its
+// own span, which the handler sees as the parent in input.Context().
+func runHandler(ctx context.Context, h Handler, input types.HookInput, eventName types.EventName, index int) HandlerResult {
+	start := time.Now()
+	hctx, span := tracing.Start(ctx, "hook.handler")
+	if span != nil {
+		span.SetAttribute("hook.handler.name", Name(h))
+		span.SetAttribute("hook.handler.index", index)
+		input = types.WithContext(input, hctx)
+	}
+
+	output, err := h.HandleEvent(input, eventName)
+
+	if span != nil {
+		decision, _ := Outcome(output, err)
+		span.SetAttribute("hook.decision", decision)
+		span.SetError(err)
+		span.End()
+	}
+	return HandlerResult{
+		Output:   output,
+		Error:    err,
+		Index:    index,
+		Duration: time.Since(start),
+	}
+}
+
 func isBlocking(output types.HookOutput) bool {
 	return output.ExitWith() == types.ExitBlocking
 }
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
 )
 
@@ -139,3 +142,39 @@ func AdaptPreCompact(h PreCompactHandler) Handler {
 func AdaptSessionStart(h SessionStartHandler) Handler {
 	return &HandlerAdapter{SessionStart: h}
 }
+
+// Namer lets a handler choose the name it is reported under in logs,
+// metrics and configuration.
+type Namer interface {
+	HandlerName() string
+}
+
+// Name returns the name of h: its HandlerName if it implements Namer,
+// otherwise its type name. Adapters report the handler they wrap.
+func Name(h Handler) string {
+	if adapter, ok := h.(*HandlerAdapter); ok {
+		if inner := adapter.wrapped(); inner != nil {
+			return nameOf(inner)
+		}
+	}
+	return nameOf(h)
+}
+
+func nameOf(h interface{}) string {
+	if n, ok := h.(Namer); ok {
+		return n.HandlerName()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", h), "*")
+}
+
+func (h *HandlerAdapter) wrapped() interface{} {
+	for _, inner := range []interface{}{
+		h.PreToolUse, h.PostToolUse, h.Notification, h.UserPromptSubmit,
+		h.Stop, h.SubagentStop, h.PreCompact, h.SessionStart,
+	} {
+		if inner != nil {
+			return inner
+		}
+	}
+	return nil
+}
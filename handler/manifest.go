@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// ManifestEnv makes a hook binary print its Manifest as JSON and exit
+// instead of handling an event. Installers use it to find out which events
+// a binary handles.
+const ManifestEnv = "CLAUDE_HOOKS_MANIFEST"
+
+// Manifest describes the events a router handles.
+type Manifest struct {
+	Events []EventManifest `json:"events"`
+}
+
+type EventManifest struct {
+	Event types.EventName `json:"event"`
+	// Matcher is the pattern the host matches against the tool name (or the
+	// compaction trigger and session source). Empty matches everything.
+	Matcher  string        `json:"matcher,omitempty"`
+	Timeout  time.Duration `json:"timeout"`
+	Handlers []string      `json:"handlers"`
+}
+
+// WithMatcher sets the matcher installed for eventName, e.g. "Bash|Write"
+// for PreToolUse. The host applies it before launching the hook; the router
+// does not check it again.
+func (r *Router) WithMatcher(eventName types.EventName, matcher string) *Router {
+	if r.config.matchers == nil {
+		r.config.matchers = make(map[types.EventName]string)
+	}
+	r.config.matchers[eventName] = matcher
+	return r
+}
+
+// Manifest lists the events with at least one handler, in the order the
+// host documents them.
+func (r *Router) Manifest() Manifest {
+	var m Manifest
+	for event, handlers := range r.config.handlers {
+		if len(handlers) == 0 {
+			continue
+		}
+		names := make([]string, len(handlers))
+		for i, h := range handlers {
+			names[i] = Name(h)
+		}
+		m.Events = append(m.Events, EventManifest{
+			Event:    event,
+			Matcher:  r.config.matchers[event],
+			Timeout:  r.config.timeout,
+			Handlers: names,
+		})
+	}
+	sort.Slice(m.Events, func(i, j int) bool {
+		return eventOrder(m.Events[i].Event) < eventOrder(m.Events[j].Event)
+	})
+	return m
+}
+
+var events = []types.EventName{
+	types.EventPreToolUse,
+	types.EventPostToolUse,
+	types.EventNotification,
+	types.EventUserPromptSubmit,
+	types.EventStop,
+	types.EventSubagentStop,
+	types.EventPreCompact,
+	types.EventSessionStart,
+}
+
+func eventOrder(e types.EventName) int {
+	for i, known := range events {
+		if e == known {
+			return i
+		}
+	}
+	return len(events)
+}
+
+// printManifestIfRequested writes the manifest and exits when ManifestEnv
+// is set.
+func (r *Router) printManifestIfRequested() {
+	if os.Getenv(ManifestEnv) == "" {
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r.Manifest()); err != nil {
+		types.Exit(1)
+	}
+	types.Exit(0)
+}
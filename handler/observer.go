@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Invocation describes one event routed by a Router, after its handlers ran
+// and their results were resolved.
+type Invocation struct {
+	ID        string
+	EventName types.EventName
+	Input     types.HookInput
+	Handlers  []Handler
+	Results   []HandlerResult
+	Output    types.HookOutput
+	Err       error
+	// Shadow is the result of the event's shadow handlers, or nil if it has
+	// none.
+	Shadow *ShadowResult
+	// Notes holds what the live handlers recorded with Note, or nil.
+	Notes    map[string]interface{}
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Observer is notified of every invocation, including events with no
+// registered handlers. Observers run before HandleEvent returns, with
+// Input.Context() still live.
+type Observer interface {
+	Observe(inv Invocation)
+}
+
+type ObserverFunc func(inv Invocation)
+
+func (f ObserverFunc) Observe(inv Invocation) {
+	f(inv)
+}
+
+type invocationIDKey struct{}
+
+// InvocationID returns the ID the router assigned to the invocation carried
+// by ctx, or "" outside of one.
+func InvocationID(ctx context.Context) string {
+	id, _ := ctx.Value(invocationIDKey{}).(string)
+	return id
+}
+
+type notesKey struct{}
+
+type notes struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// Note records value under key on the invocation carried by ctx, for
+// observers to read from Invocation.Notes. It does nothing outside of an
+// invocation and in shadow handlers.
+func Note(ctx context.Context, key string, value interface{}) {
+	n, _ := ctx.Value(notesKey{}).(*notes)
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.values == nil {
+		n.values = make(map[string]interface{})
+	}
+	n.values[key] = value
+}
+
+// snapshot copies the notes, so that handlers still running after a
+// timeout cannot change what observers see.
+func (n *notes) snapshot() map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.values) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(n.values))
+	for k, v := range n.values {
+		values[k] = v
+	}
+	return values
+}
+
+func newInvocationID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return time.Now().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b[:])
+}
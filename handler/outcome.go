@@ -0,0 +1,53 @@
+package handler
+
+import "github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+
+// Outcomes reported by Outcome besides the permission decisions.
+const (
+	OutcomeContinue = "continue"
+	OutcomeBlock    = "block"
+	OutcomeError    = "error"
+)
+
+type stopReasoner interface {
+	GetStopReason() string
+}
+
+// decisionBlocker is implemented by outputs that block through a JSON
+// decision rather than the exit code, such as types.StopOutput and
+// types.PostToolUseOutput.
+type decisionBlocker interface {
+	Blocked() bool
+	GetReason() string
+}
+
+// Outcome summarises a handler or resolved result as a single decision and
+// reason: the permission decision for PreToolUse outputs that carry one,
+// otherwise "block" or "continue" depending on the exit code, or "error".
+func Outcome(output types.HookOutput, err error) (decision, reason string) {
+	if err != nil {
+		return OutcomeError, err.Error()
+	}
+	if output == nil {
+		return OutcomeContinue, ""
+	}
+	if pre, ok := output.(types.PreToolUseOutput); ok {
+		if d := pre.Decision(); d != "" {
+			reason := pre.Reason()
+			if reason == "" {
+				reason = pre.GetStopReason()
+			}
+			return string(d), reason
+		}
+	}
+	if b, ok := output.(decisionBlocker); ok && b.Blocked() {
+		return OutcomeBlock, b.GetReason()
+	}
+	if s, ok := output.(stopReasoner); ok {
+		reason = s.GetStopReason()
+	}
+	if output.ExitWith() == types.ExitBlocking {
+		return OutcomeBlock, reason
+	}
+	return OutcomeContinue, reason
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
 )
@@ -14,6 +15,28 @@ const (
 	ResolutionModeMerge
 )
 
+func (m ResolutionMode) String() string {
+	switch m {
+	case ResolutionModeBlockAny:
+		return "block_any"
+	case ResolutionModeFirstWin:
+		return "first_win"
+	case ResolutionModeMerge:
+		return "merge"
+	}
+	return "unknown"
+}
+
+// ParseResolutionMode parses the names returned by String.
+func ParseResolutionMode(s string) (ResolutionMode, error) {
+	for _, m := range []ResolutionMode{ResolutionModeBlockAny, ResolutionModeFirstWin, ResolutionModeMerge} {
+		if s == m.String() {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown resolution mode %q (want block_any, first_win or merge)", s)
+}
+
 type Resolver interface {
 	Resolve(results []HandlerResult) (types.HookOutput, error)
 }
@@ -36,16 +59,45 @@ func (r *BlockAnyResolver) Resolve(results []HandlerResult) (types.HookOutput, e
 		}
 	}
 
+	// An "ask" decision must reach the user even if later handlers allowed
+	if output := firstAsk(results); output != nil {
+		return output, nil
+	}
+
+	if output := firstDecisionBlock(results); output != nil {
+		return output, nil
+	}
+
 	// If no blocking results, return the last successful result
 	for i := len(results) - 1; i >= 0; i-- {
 		if results[i].Output != nil {
-			return results[i].Output, nil
+			return keepPrompt(joinContext(results[i].Output, results), results), nil
 		}
 	}
 
 	return types.Success(), nil
 }
 
+func firstAsk(results []HandlerResult) types.HookOutput {
+	for _, result := range results {
+		if output, ok := result.Output.(types.PreToolUseOutput); ok && output.Decision() == types.PermissionAsk {
+			return output
+		}
+	}
+	return nil
+}
+
+// firstDecisionBlock returns the first output blocking through its JSON
+// decision, which exits 0 and would otherwise lose to later handlers.
+func firstDecisionBlock(results []HandlerResult) types.HookOutput {
+	for _, result := range results {
+		if b, ok := result.Output.(decisionBlocker); ok && b.Blocked() {
+			return result.Output
+		}
+	}
+	return nil
+}
+
 type FirstWinResolver struct{}
 
 func (r *FirstWinResolver) Resolve(results []HandlerResult) (types.HookOutput, error) {
@@ -88,6 +140,14 @@ func (r *MergeResolver) Resolve(results []HandlerResult) (types.HookOutput, erro
 		}
 	}
 
+	if output := firstAsk(results); output != nil {
+		return output, nil
+	}
+
+	if output := firstDecisionBlock(results); output != nil {
+		return output, nil
+	}
+
 	// For merge, we'll implement type-specific merging
 	// This is a simplified version - in a real implementation,
 	// you'd need type-specific merge logic for each output type
@@ -100,13 +160,84 @@ func (r *MergeResolver) mergeOutputs(results []HandlerResult) (types.HookOutput,
 	// compatible fields from multiple outputs
 	for _, result := range results {
 		if result.Output != nil {
-			return result.Output, nil
+			return keepPrompt(joinContext(result.Output, results), results), nil
 		}
 	}
 
 	return types.Success(), nil
 }
 
+type contextCarrier interface {
+	GetAdditionalContext() string
+}
+
+// joinContext sets the additional context of output to that of every
+// result, in order, so context added by one handler survives the output of
+// another.
+func joinContext(output types.HookOutput, results []HandlerResult) types.HookOutput {
+	var parts []string
+	for _, result := range results {
+		if c, ok := result.Output.(contextCarrier); ok && c.GetAdditionalContext() != "" {
+			parts = append(parts, c.GetAdditionalContext())
+		}
+	}
+	if len(parts) == 0 {
+		return output
+	}
+	joined := strings.Join(parts, "\n\n")
+	// A generic output, such as types.Success(), takes the type of the
+	// outputs carrying context.
+	if base, ok := output.(types.BaseOutput); ok {
+	typed:
+		for _, result := range results {
+			switch result.Output.(type) {
+			case types.UserPromptSubmitOutput:
+				output = types.UserPromptSubmitOutput{BaseOutput: base}
+				break typed
+			case types.SessionStartOutput:
+				output = types.SessionStartOutput{BaseOutput: base}
+				break typed
+			}
+		}
+	}
+	switch out := output.(type) {
+	case types.UserPromptSubmitOutput:
+		if joined != out.GetAdditionalContext() {
+			return out.WithAdditionalContext(joined)
+		}
+	case types.SessionStartOutput:
+		if joined != out.GetAdditionalContext() {
+			return out.WithAdditionalContext(joined)
+		}
+	}
+	return output
+}
+
+// keepPrompt sets the modified prompt of output, unless it has one, to the
+// last one a result carries, so a prompt rewritten by one handler survives
+// the output of another.
+func keepPrompt(output types.HookOutput, results []HandlerResult) types.HookOutput {
+	var prompt *string
+	for _, result := range results {
+		if out, ok := result.Output.(types.UserPromptSubmitOutput); ok && out.ModifiedPrompt != nil {
+			prompt = out.ModifiedPrompt
+		}
+	}
+	if prompt == nil {
+		return output
+	}
+	switch out := output.(type) {
+	case types.UserPromptSubmitOutput:
+		if out.ModifiedPrompt == nil {
+			out.ModifiedPrompt = prompt
+			return out
+		}
+	case types.BaseOutput:
+		return types.UserPromptSubmitOutput{BaseOutput: out, ModifiedPrompt: prompt}
+	}
+	return output
+}
+
 type CustomResolver struct {
 	ResolveFunc func(results []HandlerResult) (types.HookOutput, error)
 }
@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"reflect"
 	"time"
 
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/callkey"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/logging"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/tracing"
 	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
 )
 
@@ -15,6 +21,15 @@ type RouterConfig struct {
 	executionMode  ExecutionMode
 	resolutionMode ResolutionMode
 	timeout        time.Duration
+	sessions       *state.Store
+	observers      []Observer
+	logger         *slog.Logger
+	tracer         *tracing.Tracer
+	matchers       map[types.EventName]string
+	shadows        map[types.EventName][]Handler
+	shadowGrace    time.Duration
+	disabled       map[string]bool
+	selector       func(types.HookInput) *Router
 }
 
 type Router struct {
@@ -28,10 +43,20 @@ func NewRouter() *Router {
 			executionMode:  ExecutionModeSync,
 			resolutionMode: ResolutionModeBlockAny,
 			timeout:        30 * time.Second,
+			shadowGrace:    DefaultShadowGrace,
 		},
 	}
 }
 
+// On registers general handlers for eventName, replacing any handlers
+// previously registered for that event.
+func (r *Router) On(eventName types.EventName, handlers ...Handler) *Router {
+	eventHandlers := make([]Handler, len(handlers))
+	copy(eventHandlers, handlers)
+	r.config.handlers[eventName] = eventHandlers
+	return r
+}
+
 func (r *Router) OnPreToolUse(handlers ...PreToolUseHandler) *Router {
 	eventHandlers := make([]Handler, len(handlers))
 	for i, h := range handlers {
@@ -119,22 +144,162 @@ func (r *Router) WithTimeout(timeout time.Duration) *Router {
 	return r
 }
 
+// WithSessionStore sets the store backing the session state handlers reach
+// through state.FromContext(input.Context()). The default is state.Default().
+func (r *Router) WithSessionStore(store *state.Store) *Router {
+	r.config.sessions = store
+	return r
+}
+
+// WithObserver adds an observer notified after every event. A nil observer
+// is ignored, so optional observers such as audit.FromEnv() can be passed
+// directly.
+func (r *Router) WithObserver(observer Observer) *Router {
+	if observer == nil || isNilPointer(observer) {
+		return r
+	}
+	r.config.observers = append(r.config.observers, observer)
+	return r
+}
+
+func isNilPointer(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// WithLogger sets the logger handlers reach through input.Logger(). The
+// default is logging.Default(), which discards everything unless configured
+// through the environment.
+func (r *Router) WithLogger(logger *slog.Logger) *Router {
+	r.config.logger = logger
+	return r
+}
+
+// WithTracer enables tracing of every invocation. A nil tracer, such as
+// tracing.FromEnv() with the variable unset, leaves tracing off.
+func (r *Router) WithTracer(tracer *tracing.Tracer) *Router {
+	r.config.tracer = tracer
+	return r
+}
+
+// Disable turns off the handlers with the given names, as reported by
+// Name, for every event including shadow registrations. Disabled handlers
+// stay registered and can be turned back on with Enable.
+func (r *Router) Disable(names ...string) *Router {
+	if r.config.disabled == nil {
+		r.config.disabled = make(map[string]bool)
+	}
+	for _, name := range names {
+		r.config.disabled[name] = true
+	}
+	return r
+}
+
+// Enable turns handlers turned off by Disable back on.
+func (r *Router) Enable(names ...string) *Router {
+	for _, name := range names {
+		delete(r.config.disabled, name)
+	}
+	return r
+}
+
+// WithSelector makes the router hand each event to the router fn returns
+// for its input, for example one configured for the input's project. When
+// fn returns nil or the router itself, the router handles the event.
+func (r *Router) WithSelector(fn func(input types.HookInput) *Router) *Router {
+	r.config.selector = fn
+	return r
+}
+
+// Clone returns a copy of the router that can be reconfigured without
+// affecting r. Handlers and observers are shared.
+func (r *Router) Clone() *Router {
+	c := &Router{config: r.config}
+	c.config.handlers = cloneHandlers(r.config.handlers)
+	c.config.shadows = cloneHandlers(r.config.shadows)
+	c.config.observers = append([]Observer(nil), r.config.observers...)
+	c.config.matchers = make(map[types.EventName]string, len(r.config.matchers))
+	for event, matcher := range r.config.matchers {
+		c.config.matchers[event] = matcher
+	}
+	c.config.disabled = make(map[string]bool, len(r.config.disabled))
+	for name := range r.config.disabled {
+		c.config.disabled[name] = true
+	}
+	return c
+}
+
+func cloneHandlers(m map[types.EventName][]Handler) map[types.EventName][]Handler {
+	c := make(map[types.EventName][]Handler, len(m))
+	for event, handlers := range m {
+		c[event] = append([]Handler(nil), handlers...)
+	}
+	return c
+}
+
+// enabled filters out disabled handlers.
+func (r *Router) enabled(handlers []Handler) []Handler {
+	if len(r.config.disabled) == 0 {
+		return handlers
+	}
+	var out []Handler
+	for _, h := range handlers {
+		if !r.config.disabled[Name(h)] {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func (r *Router) sessionStore() *state.Store {
+	if r.config.sessions != nil {
+		return r.config.sessions
+	}
+	return state.Default()
+}
+
 func (r *Router) Run() error {
+	r.printManifestIfRequested()
 	return r.RunWithReader(os.Stdin)
 }
 
 func (r *Router) RunWithReader(reader io.Reader) error {
-	input, err := io.ReadAll(reader)
+	start := time.Now()
+	data, err := io.ReadAll(reader)
 	if err != nil {
 		return fmt.Errorf("failed to read input: %w", err)
 	}
 
-	output, err := r.Process(input)
+	hookInput, eventName, err := types.ParseInput(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+	parsed := time.Now()
+
+	ctx, span := tracing.Start(r.traceContext(hookInput.Context(), hookInput), "hook.run")
+	span.SetStart(start)
+	_, parseSpan := tracing.Start(ctx, "hook.parse")
+	parseSpan.SetStart(start)
+	parseSpan.EndAt(parsed)
+
+	output, err := r.HandleEvent(types.WithContext(hookInput, ctx), eventName)
 	if err != nil {
+		span.SetError(err)
+		span.End()
 		return err
 	}
 
-	types.OutputAndExit(output)
+	_, outputSpan := tracing.Start(ctx, "hook.output")
+	err = types.WriteOutput(os.Stdout, output)
+	outputSpan.SetError(err)
+	outputSpan.End()
+	span.End()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
+		types.Exit(1)
+	}
+	types.WriteFeedback(os.Stderr, output)
+	types.Exit(output.ExitWith())
 	return nil
 }
 
@@ -148,29 +313,196 @@ func (r *Router) Process(input []byte) (types.HookOutput, error) {
 }
 
 func (r *Router) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
-	handlers, exists := r.config.handlers[eventName]
-	if !exists || len(handlers) == 0 {
-		return types.Success(), nil
+	if r.config.selector != nil {
+		if selected := r.config.selector(input); selected != nil && selected != r {
+			return selected.HandleEvent(input, eventName)
+		}
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), r.config.timeout)
+	start := time.Now()
+	id := newInvocationID()
+	ctx, span := tracing.Start(r.traceContext(input.Context(), input), "hook.invocation")
+	ctx, cancel := context.WithTimeout(ctx, r.config.timeout)
 	defer cancel()
+	ctx = context.WithValue(ctx, invocationIDKey{}, id)
+	notes := &notes{}
+	ctx = context.WithValue(ctx, notesKey{}, notes)
+	ctx = state.NewContext(ctx, r.sessionStore().Session(input.GetCWD(), input.GetSessionID()))
+	logger := r.invocationLogger(input, eventName, id)
+	ctx = logging.NewContext(ctx, logger)
+	input = types.WithContext(input, ctx)
+
+	handlers := r.enabled(r.config.handlers[eventName])
+	waitShadow := r.startShadow(ctx, input, eventName)
+	results, output, err := r.dispatch(ctx, input, eventName, handlers)
+	shadow := waitShadow()
+	r.logResults(ctx, logger, handlers, results, output, err)
+	if shadow != nil {
+		r.logShadow(ctx, logger, shadow, output, err)
+	}
+	if span != nil {
+		decision, _ := Outcome(output, err)
+		span.SetAttributes(
+			tracing.Attribute{Key: "hook.event", Value: string(eventName)},
+			tracing.Attribute{Key: "hook.invocation_id", Value: id},
+			tracing.Attribute{Key: "session.id", Value: input.GetSessionID()},
+			tracing.Attribute{Key: "hook.decision", Value: decision},
+		)
+		if tool := toolName(input); tool != "" {
+			span.SetAttribute("tool.name", tool)
+		}
+		span.SetError(err)
+		span.End()
+	}
+	r.notify(Invocation{
+		ID:        id,
+		EventName: eventName,
+		Input:     input,
+		Handlers:  handlers,
+		Results:   results,
+		Output:    output,
+		Err:       err,
+		Shadow:    shadow,
+		Notes:     notes.snapshot(),
+		Start:     start,
+		Duration:  time.Since(start),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
 
+func (r *Router) dispatch(ctx context.Context, input types.HookInput, eventName types.EventName, handlers []Handler) ([]HandlerResult, types.HookOutput, error) {
+	if len(handlers) == 0 {
+		return nil, types.Success(), nil
+	}
+
+	executeCtx, executeSpan := tracing.Start(ctx, "hook.execute")
+	executeSpan.SetAttribute("hook.execution", r.config.executionMode.String())
 	executor := GetExecutor(r.config.executionMode)
-	results, err := executor.Execute(ctx, input, eventName, handlers)
+	results, err := executor.Execute(executeCtx, input, eventName, handlers)
+	executeSpan.SetError(err)
+	executeSpan.End()
 	if err != nil {
-		return nil, err
+		return results, nil, err
 	}
 
+	// Resolvers take no context, so the span is opened around the call.
+	_, resolveSpan := tracing.Start(ctx, "hook.resolve")
+	resolveSpan.SetAttribute("hook.resolution", r.config.resolutionMode.String())
 	resolver := GetResolver(r.config.resolutionMode)
-	return resolver.Resolve(results)
+	output, err := resolver.Resolve(results)
+	resolveSpan.SetError(err)
+	resolveSpan.End()
+	return results, output, err
+}
+
+// traceContext enables tracing for an invocation that does not already
+// carry a tracer. Its spans join the session's trace and, for tool events,
+// become children of the tool call's span.
+func (r *Router) traceContext(ctx context.Context, input types.HookInput) context.Context {
+	if r.config.tracer == nil || tracing.Enabled(ctx) {
+		return ctx
+	}
+	ctx = tracing.NewContext(ctx, r.config.tracer)
+	var parent tracing.SpanID
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		parent = tracing.ToolCallSpanID(in.SessionID, callkey.Key(string(in.ToolName), in.ToolUseID, in.ToolInput))
+	case types.PostToolUseInput:
+		parent = tracing.ToolCallSpanID(in.SessionID, callkey.Key(string(in.ToolName), in.ToolUseID, in.ToolInput))
+	}
+	return tracing.WithParent(ctx, tracing.SessionTraceID(input.GetSessionID()), parent)
+}
+
+func toolName(input types.HookInput) string {
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		return string(in.ToolName)
+	case types.PostToolUseInput:
+		return string(in.ToolName)
+	}
+	return ""
+}
+
+func (r *Router) invocationLogger(input types.HookInput, eventName types.EventName, id string) *slog.Logger {
+	logger := r.config.logger
+	if logger == nil {
+		logger = logging.Default()
+	}
+	attrs := []any{
+		slog.String(logging.KeyInvocationID, id),
+		slog.String(logging.KeySessionID, input.GetSessionID()),
+		slog.String(logging.KeyEvent, string(eventName)),
+	}
+	if tool := toolName(input); tool != "" {
+		attrs = append(attrs, slog.String(logging.KeyToolName, tool))
+	}
+	return logger.With(attrs...)
+}
+
+// logResults logs each handler result and the resolved outcome at debug
+// level, and failures at error level.
+func (r *Router) logResults(ctx context.Context, logger *slog.Logger, handlers []Handler, results []HandlerResult, output types.HookOutput, err error) {
+	if err != nil {
+		logger.ErrorContext(ctx, "hook failed", slog.Any("error", err))
+	}
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	logger.DebugContext(ctx, "executed handlers",
+		slog.String("execution", r.config.executionMode.String()),
+		slog.Int("registered", len(handlers)),
+		slog.Int("ran", len(results)))
+	for _, result := range results {
+		decision, reason := Outcome(result.Output, result.Error)
+		name := ""
+		if result.Index < len(handlers) {
+			name = Name(handlers[result.Index])
+		}
+		logger.DebugContext(ctx, "handler result",
+			slog.Int("index", result.Index),
+			slog.String("handler", name),
+			slog.String("decision", decision),
+			slog.String("reason", reason),
+			slog.Duration("duration", result.Duration))
+	}
+	decision, reason := Outcome(output, err)
+	logger.DebugContext(ctx, "resolved",
+		slog.String("resolution", r.config.resolutionMode.String()),
+		slog.String("decision", decision),
+		slog.String("reason", reason))
+}
+
+func (r *Router) logShadow(ctx context.Context, logger *slog.Logger, shadow *ShadowResult, output types.HookOutput, err error) {
+	level := slog.LevelDebug
+	if shadow.Diverges(output, err) || shadow.TimedOut {
+		level = slog.LevelInfo
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	decision, reason := Outcome(shadow.Output, shadow.Err)
+	live, _ := Outcome(output, err)
+	logger.Log(ctx, level, "shadow result",
+		slog.String("decision", decision),
+		slog.String("reason", reason),
+		slog.String("live_decision", live),
+		slog.Bool("diverges", live != decision))
+}
+
+func (r *Router) notify(inv Invocation) {
+	for _, observer := range r.config.observers {
+		observer.Observe(inv)
+	}
 }
 
 // Convenience functions for simple use cases
 func Execute(router *Router) {
 	if err := router.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Hook execution failed: %v\n", err)
-		os.Exit(1)
+		types.Exit(1)
 	}
 }
 
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/safecall"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/tracing"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DefaultShadowGrace is how long the router waits for the shadow handlers
+// once the live ones are done.
+const DefaultShadowGrace = 100 * time.Millisecond
+
+// ErrShadowTimeout is the error of a shadow result whose handlers had not
+// finished when the grace period ran out.
+var ErrShadowTimeout = errors.New("shadow handlers did not finish in time")
+
+// ShadowResult is what the shadow handlers of an event would have decided.
+// It is reported to observers and never affects the router's output.
+// TimedOut is set, with Err set to ErrShadowTimeout, when the router
+// stopped waiting for them.
+type ShadowResult struct {
+	Handlers []Handler
+	Results  []HandlerResult
+	Output   types.HookOutput
+	Err      error
+	TimedOut bool
+}
+
+// Diverges reports whether the shadow decision differs from the live one,
+// comparing the decisions reported by Outcome. A result that timed out
+// does not diverge: its decision is unknown.
+func (s *ShadowResult) Diverges(output types.HookOutput, err error) bool {
+	if s.TimedOut {
+		return false
+	}
+	live, _ := Outcome(output, err)
+	shadow, _ := Outcome(s.Output, s.Err)
+	return live != shadow
+}
+
+// Shadow registers handlers for eventName that run in shadow mode: they see
+// every event the live handlers see, with the same execution and resolution
+// modes, but their result only reaches observers such as the audit log,
+// through Invocation.Shadow. Errors and panics in shadow handlers are
+// recorded rather than returned. Use it to try a new policy before
+// enforcing it. Side effects of the handlers, such as rate limit counters,
+// still happen.
+//
+// The router does not hold the live output back for slow shadow handlers:
+// it waits for them for the grace period set with WithShadowGrace once the
+// live handlers are done, and then records the shadow result as timed out.
+func (r *Router) Shadow(eventName types.EventName, handlers ...Handler) *Router {
+	if r.config.shadows == nil {
+		r.config.shadows = make(map[types.EventName][]Handler)
+	}
+	eventHandlers := make([]Handler, len(handlers))
+	copy(eventHandlers, handlers)
+	r.config.shadows[eventName] = eventHandlers
+	return r
+}
+
+// WithShadowGrace sets how long the router waits for the shadow handlers
+// once the live ones are done. The default is DefaultShadowGrace.
+func (r *Router) WithShadowGrace(grace time.Duration) *Router {
+	r.config.shadowGrace = grace
+	return r
+}
+
+// startShadow runs the shadow handlers of eventName concurrently with the
+// live ones. The returned function waits for them, up to the grace period.
+func (r *Router) startShadow(ctx context.Context, input types.HookInput, eventName types.EventName) func() *ShadowResult {
+	handlers := r.enabled(r.config.shadows[eventName])
+	if len(handlers) == 0 {
+		return func() *ShadowResult { return nil }
+	}
+
+	// Notes describe what the live handlers did.
+	ctx = context.WithValue(ctx, notesKey{}, (*notes)(nil))
+	input = types.WithContext(input, ctx)
+	done := make(chan *ShadowResult, 1)
+	go func() {
+		shadow := &ShadowResult{Handlers: handlers}
+		defer func() {
+			if p := recover(); p != nil {
+				shadow.Err = fmt.Errorf("shadow handler panicked: %v", p)
+			}
+			done <- shadow
+		}()
+
+		ctx, span := tracing.Start(ctx, "hook.shadow")
+		defer span.End()
+		guarded := make([]Handler, len(handlers))
+		for i, h := range handlers {
+			guarded[i] = recovering{h}
+		}
+		shadow.Results, shadow.Err = GetExecutor(r.config.executionMode).Execute(ctx, input, eventName, guarded)
+		if shadow.Err == nil {
+			shadow.Output, shadow.Err = GetResolver(r.config.resolutionMode).Resolve(shadow.Results)
+		}
+		span.SetError(shadow.Err)
+	}()
+	return func() *ShadowResult {
+		timer := time.NewTimer(r.config.shadowGrace)
+		defer timer.Stop()
+		select {
+		case shadow := <-done:
+			return shadow
+		case <-timer.C:
+			return &ShadowResult{Handlers: handlers, Err: ErrShadowTimeout, TimedOut: true}
+		}
+	}
+}
+
+// recovering turns a panic in a shadow handler into its error, so that a
+// broken rule under evaluation cannot take the hook down.
+type recovering struct {
+	Handler
+}
+
+func (h recovering) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	return safecall.Do(func() (types.HookOutput, error) {
+		return h.Handler.HandleEvent(input, eventName)
+	})
+}
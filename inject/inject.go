@@ -0,0 +1,228 @@
+// Package inject adds project context to the model's context window on
+// UserPromptSubmit and SessionStart. Providers contribute snippets, such as
+// the git status or a notes file, each with a priority and a size budget;
+// a Registry gathers them, keeps the most important ones within a total
+// character budget and returns them as additionalContext.
+package inject
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/safecall"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Priorities of the built-in providers. Snippets with a higher priority
+// come first and are the last to be cut.
+const (
+	PriorityHigh   = 100
+	PriorityNormal = 50
+	PriorityLow    = 10
+)
+
+const (
+	// DefaultBudget is the default total size of the context, in
+	// characters.
+	DefaultBudget = 8000
+	// minFragment is the smallest truncated snippet worth including.
+	minFragment = 200
+
+	truncated = "\n... (truncated)"
+)
+
+// Snippet is one piece of context. Budget caps its text in characters; zero
+// means only the registry's total budget applies.
+type Snippet struct {
+	Title    string
+	Text     string
+	Priority int
+	Budget   int
+}
+
+// Provider returns the snippets for an event. Providers run concurrently;
+// input.Context() carries the router's deadline.
+type Provider interface {
+	Provide(input types.HookInput) ([]Snippet, error)
+}
+
+type ProviderFunc func(input types.HookInput) ([]Snippet, error)
+
+func (f ProviderFunc) Provide(input types.HookInput) ([]Snippet, error) {
+	return f(input)
+}
+
+// Tuned overrides the priority and budget of the snippets p returns.
+func Tuned(p Provider, priority, budget int) Provider {
+	return ProviderFunc(func(input types.HookInput) ([]Snippet, error) {
+		snippets, err := p.Provide(input)
+		for i := range snippets {
+			snippets[i].Priority = priority
+			snippets[i].Budget = budget
+		}
+		return snippets, err
+	})
+}
+
+// Registry is a UserPromptSubmit and SessionStart handler adding the
+// snippets of its providers as additionalContext:
+//
+//	registry := inject.New().
+//	    Register("git", inject.Git()).
+//	    Register("issues", inject.File("Open issues", ".claude/issues.md"), types.EventSessionStart)
+//	router := handler.NewRouter().
+//	    On(types.EventUserPromptSubmit, registry).
+//	    On(types.EventSessionStart, registry)
+//
+// A provider that fails is logged and left out.
+type Registry struct {
+	providers []registered
+	budget    int
+}
+
+type registered struct {
+	name     string
+	provider Provider
+	events   []types.EventName
+}
+
+func New() *Registry {
+	return &Registry{budget: DefaultBudget}
+}
+
+// WithBudget sets the total size of the context in characters.
+func (r *Registry) WithBudget(budget int) *Registry {
+	r.budget = budget
+	return r
+}
+
+// Register adds a provider for the given events, or for both
+// UserPromptSubmit and SessionStart if none are given.
+func (r *Registry) Register(name string, p Provider, events ...types.EventName) *Registry {
+	r.providers = append(r.providers, registered{name: name, provider: p, events: events})
+	return r
+}
+
+func (r *Registry) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	switch in := input.(type) {
+	case types.UserPromptSubmitInput:
+		return r.HandleUserPromptSubmit(in)
+	case types.SessionStartInput:
+		return r.HandleSessionStart(in)
+	}
+	return types.Success(), nil
+}
+
+func (r *Registry) HandleUserPromptSubmit(input types.UserPromptSubmitInput) (types.UserPromptSubmitOutput, error) {
+	var output types.UserPromptSubmitOutput
+	if text := r.Assemble(input); text != "" {
+		output = output.WithAdditionalContext(text)
+	}
+	return output, nil
+}
+
+func (r *Registry) HandleSessionStart(input types.SessionStartInput) (types.SessionStartOutput, error) {
+	var output types.SessionStartOutput
+	if text := r.Assemble(input); text != "" {
+		output = output.WithAdditionalContext(text)
+	}
+	return output, nil
+}
+
+// Assemble runs the providers registered for the input's event and joins
+// their snippets, highest priority first, within the budget.
+func (r *Registry) Assemble(input types.HookInput) string {
+	event := types.EventName(input.GetEventName())
+	results := make([][]Snippet, len(r.providers))
+	var wg sync.WaitGroup
+	for i, p := range r.providers {
+		if len(p.events) > 0 && !contains(p.events, event) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			snippets, err := safecall.Do(func() ([]Snippet, error) {
+				return p.provider.Provide(input)
+			})
+			if err != nil {
+				input.Logger().Warn("context provider failed", "provider", p.name, "error", err)
+				return
+			}
+			results[i] = snippets
+		}()
+	}
+	wg.Wait()
+
+	var snippets []Snippet
+	for _, s := range results {
+		snippets = append(snippets, s...)
+	}
+	return assemble(snippets, r.budget)
+}
+
+// assemble renders snippets in order of priority, cutting each to its own
+// budget and the whole to budget. A snippet that does not fit is truncated
+// if a useful part of it does and skipped otherwise, leaving room for
+// smaller ones after it.
+func assemble(snippets []Snippet, budget int) string {
+	sort.SliceStable(snippets, func(i, j int) bool {
+		return snippets[i].Priority > snippets[j].Priority
+	})
+	const separator = "\n\n"
+	var parts []string
+	remaining := budget
+	for _, s := range snippets {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		if s.Budget > 0 {
+			text = truncate(text, s.Budget)
+		}
+		if s.Title != "" {
+			text = "## " + s.Title + "\n" + text
+		}
+		if len(parts) > 0 {
+			remaining -= len(separator)
+		}
+		size := utf8.RuneCountInString(text)
+		if size > remaining {
+			if remaining < minFragment {
+				if len(parts) > 0 {
+					remaining += len(separator)
+				}
+				continue
+			}
+			text = truncate(text, remaining)
+			size = remaining
+		}
+		parts = append(parts, text)
+		remaining -= size
+	}
+	return strings.Join(parts, separator)
+}
+
+// truncate cuts s to at most n characters, marking the cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	keep := n - len(truncated)
+	if keep < 0 {
+		keep = 0
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:keep]), " \n") + truncated
+}
+
+func contains(events []types.EventName, event types.EventName) bool {
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
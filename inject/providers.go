@@ -0,0 +1,98 @@
+package inject
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DefaultMaxFiles is how many changed files Git lists.
+const DefaultMaxFiles = 30
+
+// Git provides the current branch and the files with uncommitted changes,
+// as reported by git status. Outside a git repository it provides nothing.
+func Git() Provider {
+	return ProviderFunc(func(input types.HookInput) ([]Snippet, error) {
+		branch, err := git(input, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			var exit *exec.ExitError
+			if errors.As(err, &exit) {
+				return nil, nil // not a repository, or no commits yet
+			}
+			return nil, err
+		}
+		status, err := git(input, "status", "--porcelain")
+		if err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Branch: %s\n", strings.TrimSpace(branch))
+		files := strings.Split(strings.TrimRight(status, "\n"), "\n")
+		if status == "" {
+			b.WriteString("Working tree clean")
+		} else {
+			fmt.Fprintf(&b, "Uncommitted changes (%d files):", len(files))
+			for i, file := range files {
+				if i == DefaultMaxFiles {
+					fmt.Fprintf(&b, "\n... and %d more", len(files)-i)
+					break
+				}
+				b.WriteString("\n" + file)
+			}
+		}
+		return []Snippet{{Title: "Git", Text: b.String(), Priority: PriorityNormal}}, nil
+	})
+}
+
+func git(input types.HookInput, args ...string) (string, error) {
+	cmd := exec.CommandContext(input.Context(), "git", args...)
+	cmd.Dir = input.GetCWD()
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// File provides the contents of a file, such as notes on open issues, under
+// title. A relative path is resolved against the session's working
+// directory. A missing or empty file provides nothing.
+func File(title, path string) Provider {
+	return ProviderFunc(func(input types.HookInput) ([]Snippet, error) {
+		file := path
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(input.GetCWD(), file)
+		}
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []Snippet{{Title: title, Text: string(data), Priority: PriorityNormal}}, nil
+	})
+}
+
+// Command provides the output of a command run with sh -c in the session's
+// working directory, whatever its exit code, so that a command listing
+// test failures may fail. Empty output provides nothing.
+func Command(title, command string) Provider {
+	return ProviderFunc(func(input types.HookInput) ([]Snippet, error) {
+		cmd := exec.CommandContext(input.Context(), "sh", "-c", command)
+		cmd.Dir = input.GetCWD()
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+		var exit *exec.ExitError
+		if err != nil && !errors.As(err, &exit) {
+			return nil, err
+		}
+		return []Snippet{{Title: title, Text: out.String(), Priority: PriorityLow}}, nil
+	})
+}
@@ -0,0 +1,33 @@
+// Package callkey identifies a tool call across the PreToolUse and
+// PostToolUse invocations that report on it.
+package callkey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Fingerprint returns a stable hash of a tool call. Map keys are encoded in
+// sorted order, so equal inputs always produce the same fingerprint.
+func Fingerprint(toolName string, toolInput map[string]interface{}) string {
+	data, err := json.Marshal(struct {
+		Tool  string                 `json:"tool"`
+		Input map[string]interface{} `json:"input"`
+	}{toolName, toolInput})
+	if err != nil {
+		data = []byte(fmt.Sprintf("%s:%v", toolName, toolInput))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Key returns the tool_use_id when the host provides one and the
+// fingerprint otherwise.
+func Key(toolName, toolUseID string, toolInput map[string]interface{}) string {
+	if toolUseID != "" {
+		return "id:" + toolUseID
+	}
+	return "fp:" + Fingerprint(toolName, toolInput)
+}
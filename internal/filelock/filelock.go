@@ -0,0 +1,70 @@
+// Package filelock provides exclusive advisory locks on files, used to
+// serialise read-modify-write cycles between concurrent hook processes.
+package filelock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Timeout bounds the wait for a lock when the context has no deadline, so
+// that a stuck holder cannot hang a hook until the host kills it.
+var Timeout = 10 * time.Second
+
+const maxPoll = 50 * time.Millisecond
+
+// Lock is an exclusive lock held on a lock file.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire waits until it holds an exclusive lock on path, creating the file
+// and its parent directories if needed. It gives up when ctx is done, or
+// after Timeout if ctx has no deadline.
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, Timeout)
+		defer cancel()
+	}
+	for poll := time.Millisecond; ; poll = min(2*poll, maxPoll) {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			return &Lock{f: f}, nil
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, ctx.Err())
+		case <-time.After(poll):
+		}
+	}
+}
+
+// Release unlocks and closes the lock file. The file itself is left in
+// place so that other processes keep locking the same inode.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	l.f = nil
+	return err
+}
@@ -0,0 +1,122 @@
+// Package glob implements shell-style glob patterns with support for "**".
+//
+// "*" and "?" never match a path separator, "**" matches any number of path
+// segments (including none), "[...]" is a character class and "{a,b}"
+// matches either alternative.
+package glob
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Pattern struct {
+	source string
+	re     *regexp.Regexp
+}
+
+func Compile(pattern string) (*Pattern, error) {
+	expr, err := translate(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return &Pattern{source: pattern, re: re}, nil
+}
+
+func MustCompile(pattern string) *Pattern {
+	p, err := Compile(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether name matches pattern. Invalid patterns never match.
+func Match(pattern, name string) bool {
+	p, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return p.Match(name)
+}
+
+func (p *Pattern) Match(name string) bool {
+	return p.re.MatchString(name)
+}
+
+func (p *Pattern) String() string {
+	return p.source
+}
+
+func translate(pattern string) (string, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	inGroup := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			inGroup++
+			b.WriteString("(?:")
+		case '}':
+			if inGroup == 0 {
+				b.WriteString(`\}`)
+				continue
+			}
+			inGroup--
+			b.WriteString(")")
+		case ',':
+			if inGroup > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			} else {
+				b.WriteString(`\\`)
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if inGroup > 0 {
+		return "", fmt.Errorf("unterminated group")
+	}
+
+	b.WriteString("$")
+	return b.String(), nil
+}
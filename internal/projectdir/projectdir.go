@@ -0,0 +1,50 @@
+// Package projectdir finds the root directory of the project a hook runs
+// in.
+package projectdir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Base names of the hook configuration files inside a .claude directory,
+// and the extensions they may have, preferred first.
+const (
+	ConfigBase = "hooks"
+	LocalBase  = "hooks.local"
+)
+
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// Root returns the nearest directory at or above cwd that has hook
+// configuration in its .claude directory or is the root of a git
+// repository, or cwd itself if there is none. The home directory is never
+// a project root, since its .claude directory holds the user layer.
+func Root(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for dir := filepath.Clean(cwd); ; {
+		if dir != home && isRoot(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Clean(cwd)
+		}
+		dir = parent
+	}
+}
+
+func isRoot(dir string) bool {
+	for _, base := range []string{ConfigBase, LocalBase} {
+		for _, ext := range Extensions {
+			if _, err := os.Stat(filepath.Join(dir, ".claude", base+ext)); err == nil {
+				return true
+			}
+		}
+	}
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
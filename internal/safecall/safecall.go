@@ -0,0 +1,16 @@
+// Package safecall runs user-supplied functions that must not take the hook
+// down when they panic.
+package safecall
+
+import "fmt"
+
+// Do calls fn, turning a panic into an error.
+func Do[T any](fn func() (T, error)) (result T, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			var zero T
+			result, err = zero, fmt.Errorf("panic: %v", v)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,116 @@
+// Package logging provides the log/slog loggers handed to hook handlers.
+//
+// The host parses a hook's stdout and may show its stderr to the model, so
+// by default nothing is logged at all. Set CLAUDE_HOOKS_LOG_FILE to append
+// JSON logs to a file, CLAUDE_HOOKS_LOG_LEVEL to debug, info, warn or error
+// to change the level, and CLAUDE_HOOKS_DEBUG=1 to log router decisions at
+// debug level (to stderr when no log file is set).
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	FileEnv  = "CLAUDE_HOOKS_LOG_FILE"
+	LevelEnv = "CLAUDE_HOOKS_LOG_LEVEL"
+	DebugEnv = "CLAUDE_HOOKS_DEBUG"
+)
+
+// Attribute keys set on every invocation's logger.
+const (
+	KeySessionID    = "session_id"
+	KeyEvent        = "event"
+	KeyToolName     = "tool_name"
+	KeyInvocationID = "invocation_id"
+)
+
+var discard = slog.New(slog.DiscardHandler)
+
+var (
+	defaultOnce   sync.Once
+	defaultLogger *slog.Logger
+)
+
+// Default returns the logger configured by the environment, or a logger
+// that discards everything.
+func Default() *slog.Logger {
+	defaultOnce.Do(func() {
+		logger, err := FromEnv()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logging: %v\n", err)
+			logger = discard
+		}
+		defaultLogger = logger
+	})
+	return defaultLogger
+}
+
+// FromEnv builds a logger from CLAUDE_HOOKS_LOG_FILE, CLAUDE_HOOKS_LOG_LEVEL
+// and CLAUDE_HOOKS_DEBUG.
+func FromEnv() (*slog.Logger, error) {
+	level := slog.LevelInfo
+	if s := os.Getenv(LevelEnv); s != "" {
+		if err := level.UnmarshalText([]byte(s)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", LevelEnv, err)
+		}
+	}
+	debug := DebugEnabled()
+	if debug {
+		level = slog.LevelDebug
+	}
+
+	path := os.Getenv(FileEnv)
+	switch {
+	case path != "":
+		return NewFile(path, level)
+	case debug:
+		return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})), nil
+	}
+	return discard, nil
+}
+
+// DebugEnabled reports whether CLAUDE_HOOKS_DEBUG is set to a true value.
+func DebugEnabled() bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(DebugEnv)))
+	return err == nil && v
+}
+
+// NewFile returns a logger appending JSON records to path. Each record is a
+// single write, so concurrent hook processes can share the file.
+func NewFile(path string, level slog.Leveler) (*slog.Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return slog.New(slog.NewJSONHandler(f, &slog.HandlerOptions{Level: level})), nil
+}
+
+// Discard returns a logger that drops every record.
+func Discard() *slog.Logger {
+	return discard
+}
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or Default.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return Default()
+}
@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Content types of the two exposition formats.
+const (
+	ContentTypePrometheus  = "text/plain; version=0.0.4; charset=utf-8"
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+type Format int
+
+const (
+	FormatPrometheus Format = iota
+	FormatOpenMetrics
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "prometheus", "text":
+		return FormatPrometheus, nil
+	case "openmetrics":
+		return FormatOpenMetrics, nil
+	}
+	return 0, fmt.Errorf("unknown metrics format %q (want prometheus or openmetrics)", s)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatOpenMetrics {
+		return ContentTypeOpenMetrics
+	}
+	return ContentTypePrometheus
+}
+
+// Write renders the snapshot in the given format. Families and series are
+// sorted so the output is stable.
+func (s *Snapshot) Write(w io.Writer, format Format) error {
+	bw := bufio.NewWriter(w)
+
+	counters := make(map[string][]*Counter)
+	for _, c := range s.Counters {
+		counters[c.Name] = append(counters[c.Name], c)
+	}
+	histograms := make(map[string][]*Histogram)
+	for _, h := range s.Histograms {
+		histograms[h.Name] = append(histograms[h.Name], h)
+	}
+
+	for _, name := range sortedKeys(counters) {
+		series := counters[name]
+		sort.Slice(series, func(i, j int) bool { return formatLabels(series[i].Labels) < formatLabels(series[j].Labels) })
+		family := name
+		if format == FormatOpenMetrics {
+			// OpenMetrics names the family without the _total suffix.
+			family = strings.TrimSuffix(name, "_total")
+		}
+		writeHeader(bw, family, "counter", helpFor(name))
+		sample := family + "_total"
+		if format == FormatPrometheus {
+			sample = name
+		}
+		for _, c := range series {
+			fmt.Fprintf(bw, "%s%s %s\n", sample, formatLabels(c.Labels), formatValue(c.Value))
+		}
+	}
+
+	for _, name := range sortedKeys(histograms) {
+		series := histograms[name]
+		sort.Slice(series, func(i, j int) bool { return formatLabels(series[i].Labels) < formatLabels(series[j].Labels) })
+		writeHeader(bw, name, "histogram", helpFor(name))
+		for _, h := range series {
+			var cumulative uint64
+			for i, bound := range h.Bounds {
+				cumulative += h.Counts[i]
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(withLabel(h.Labels, "le", formatValue(bound))), cumulative)
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", name, formatLabels(withLabel(h.Labels, "le", "+Inf")), h.Count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", name, formatLabels(h.Labels), formatValue(h.Sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", name, formatLabels(h.Labels), h.Count)
+		}
+	}
+
+	if format == FormatOpenMetrics {
+		bw.WriteString("# EOF\n")
+	}
+	return bw.Flush()
+}
+
+func writeHeader(w *bufio.Writer, name, kind, text string) {
+	if text != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(text))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func withLabel(labels Labels, name, value string) Labels {
+	out := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		out[k] = v
+	}
+	out[name] = value
+	return out
+}
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := sortedKeys(labels)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + `="` + escapeLabel(labels[name]) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+
+func escapeHelp(s string) string { return helpEscaper.Replace(s) }
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// WriteTextfile renders the metrics file at path in the Prometheus format
+// to out, atomically, for node_exporter's textfile collector. out should
+// end in .prom.
+func WriteTextfile(path, out string) error {
+	snapshot, err := Load(path)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := snapshot.Write(&buf, FormatPrometheus); err != nil {
+		return err
+	}
+	if err := writeAtomic(out, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write textfile: %w", err)
+	}
+	return nil
+}
+
+// Handler serves the metrics file at path, re-reading it on every request.
+// Clients that accept application/openmetrics-text get OpenMetrics, others
+// the Prometheus text format.
+func Handler(path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		snapshot, err := Load(path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		format := FormatPrometheus
+		if strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text") {
+			format = FormatOpenMetrics
+		}
+		var buf bytes.Buffer
+		if err := snapshot.Write(&buf, format); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Write(buf.Bytes())
+	})
+}
+
+// ListenAndServe serves the metrics file at path on addr under /metrics.
+// Bind it to a loopback address such as 127.0.0.1:9464.
+func ListenAndServe(addr, path string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(path))
+	return http.ListenAndServe(addr, mux)
+}
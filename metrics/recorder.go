@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/filelock"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// FileEnv names the metrics file used by FromEnv.
+const FileEnv = "CLAUDE_HOOKS_METRICS_FILE"
+
+// Metrics recorded for every invocation.
+const (
+	EventsTotal               = "claude_hook_events_total"
+	DecisionsTotal            = "claude_hook_decisions_total"
+	HandlerErrorsTotal        = "claude_hook_handler_errors_total"
+	HandlerDurationSeconds    = "claude_hook_handler_duration_seconds"
+	InvocationDurationSeconds = "claude_hook_invocation_duration_seconds"
+)
+
+var help = map[string]string{
+	EventsTotal:               "Hook events received.",
+	DecisionsTotal:            "Resolved hook decisions by event, decision and deciding handler.",
+	HandlerErrorsTotal:        "Errors returned by handlers.",
+	HandlerDurationSeconds:    "Time spent in each handler.",
+	InvocationDurationSeconds: "Time spent handling an event, including resolution.",
+}
+
+// Describe sets the HELP text rendered for a custom metric.
+func Describe(name, text string) {
+	helpMu.Lock()
+	defer helpMu.Unlock()
+	help[name] = text
+}
+
+var helpMu sync.RWMutex
+
+func helpFor(name string) string {
+	helpMu.RLock()
+	defer helpMu.RUnlock()
+	return help[name]
+}
+
+// DefaultBuckets are the histogram bounds in seconds, from 1ms to 30s.
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Recorder is a handler.Observer that counts events, decisions and handler
+// errors and records handler latency. Measurements are kept in memory and
+// merged into the metrics file by Flush, which runs automatically before
+// types.OutputAndExit or handler.Execute exit the process.
+type Recorder struct {
+	path    string
+	buckets []float64
+	onError func(error)
+
+	mu      sync.Mutex
+	pending *Snapshot
+}
+
+func NewRecorder(path string) *Recorder {
+	r := &Recorder{
+		path:    path,
+		buckets: DefaultBuckets,
+		pending: NewSnapshot(),
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "metrics: %v\n", err)
+		},
+	}
+	types.OnExit(func() {
+		if err := r.Flush(); err != nil {
+			r.onError(err)
+		}
+	})
+	return r
+}
+
+// FromEnv returns a recorder for $CLAUDE_HOOKS_METRICS_FILE, or nil if the
+// variable is unset.
+func FromEnv() *Recorder {
+	path := os.Getenv(FileEnv)
+	if path == "" {
+		return nil
+	}
+	return NewRecorder(path)
+}
+
+// WithBuckets sets the histogram bounds in seconds, in increasing order.
+func (r *Recorder) WithBuckets(bounds ...float64) *Recorder {
+	r.buckets = bounds
+	return r
+}
+
+// WithErrorHandler sets the function receiving errors from the automatic
+// flush at exit. The default writes them to stderr.
+func (r *Recorder) WithErrorHandler(fn func(error)) *Recorder {
+	r.onError = fn
+	return r
+}
+
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Add increments a counter.
+func (r *Recorder) Add(name string, labels Labels, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending.add(name, labels, v)
+}
+
+// ObserveDuration records d in a histogram, in seconds.
+func (r *Recorder) ObserveDuration(name string, labels Labels, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending.observe(name, labels, r.buckets, d.Seconds())
+}
+
+func (r *Recorder) Observe(inv handler.Invocation) {
+	event := string(inv.EventName)
+	tool := toolName(inv.Input)
+
+	r.Add(EventsTotal, Labels{"event": event, "tool": tool}, 1)
+	decision, _ := handler.Outcome(inv.Output, inv.Err)
+	r.Add(DecisionsTotal, Labels{
+		"event":    event,
+		"tool":     tool,
+		"decision": decision,
+		"handler":  decidingHandler(inv, decision),
+	}, 1)
+	r.ObserveDuration(InvocationDurationSeconds, Labels{"event": event}, inv.Duration)
+
+	for _, result := range inv.Results {
+		name := ""
+		if result.Index < len(inv.Handlers) {
+			name = handler.Name(inv.Handlers[result.Index])
+		}
+		labels := Labels{"event": event, "handler": name}
+		r.ObserveDuration(HandlerDurationSeconds, labels, result.Duration)
+		if result.Error != nil {
+			r.Add(HandlerErrorsTotal, labels, 1)
+		}
+	}
+}
+
+// Flush merges pending measurements into the metrics file.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pending.empty() {
+		return nil
+	}
+
+	lock, err := filelock.Acquire(context.Background(), r.path+".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	stored, err := Load(r.path)
+	if err != nil {
+		return err
+	}
+	r.pending.UpdatedAt = time.Now().UTC()
+	stored.Merge(r.pending)
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	if err := writeAtomic(r.path, data); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	r.pending = NewSnapshot()
+	return nil
+}
+
+func toolName(input types.HookInput) string {
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		return string(in.ToolName)
+	case types.PostToolUseInput:
+		return string(in.ToolName)
+	}
+	return ""
+}
+
+// decidingHandler returns the name of the first handler whose own result
+// has the resolved decision. Unlike the free-text reason, handler names
+// keep the number of series bounded.
+func decidingHandler(inv handler.Invocation, decision string) string {
+	for _, result := range inv.Results {
+		if d, _ := handler.Outcome(result.Output, result.Error); d == decision && result.Index < len(inv.Handlers) {
+			return handler.Name(inv.Handlers[result.Index])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,197 @@
+// Package metrics aggregates counters and histograms across hook
+// invocations. Hooks are short-lived processes, so each one merges its
+// measurements into a shared JSON file under a file lock; the file can then
+// be rendered in the Prometheus text exposition format or as OpenMetrics,
+// written for node_exporter's textfile collector or served over HTTP.
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Labels are the label names and values of one series.
+type Labels map[string]string
+
+// key identifies a series by name and labels.
+func key(name string, labels Labels) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range names {
+		b.WriteString("\x00")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(labels[k])
+	}
+	return b.String()
+}
+
+type Counter struct {
+	Name   string  `json:"name"`
+	Labels Labels  `json:"labels,omitempty"`
+	Value  float64 `json:"value"`
+}
+
+// Histogram counts observations per bucket. Bounds are the bucket upper
+// bounds in increasing order; Counts has one more element than Bounds for
+// the +Inf bucket and is not cumulative.
+type Histogram struct {
+	Name   string    `json:"name"`
+	Labels Labels    `json:"labels,omitempty"`
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Count  uint64    `json:"count"`
+	Sum    float64   `json:"sum"`
+}
+
+func newHistogram(name string, labels Labels, bounds []float64) *Histogram {
+	return &Histogram{Name: name, Labels: labels, Bounds: bounds, Counts: make([]uint64, len(bounds)+1)}
+}
+
+func (h *Histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.Bounds, v)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += v
+}
+
+func sameBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Snapshot is the aggregated state of all series.
+type Snapshot struct {
+	UpdatedAt  time.Time             `json:"updated_at"`
+	Counters   map[string]*Counter   `json:"counters"`
+	Histograms map[string]*Histogram `json:"histograms"`
+}
+
+func NewSnapshot() *Snapshot {
+	return &Snapshot{Counters: make(map[string]*Counter), Histograms: make(map[string]*Histogram)}
+}
+
+func (s *Snapshot) add(name string, labels Labels, v float64) {
+	k := key(name, labels)
+	c, ok := s.Counters[k]
+	if !ok {
+		c = &Counter{Name: name, Labels: labels}
+		s.Counters[k] = c
+	}
+	c.Value += v
+}
+
+func (s *Snapshot) observe(name string, labels Labels, bounds []float64, v float64) {
+	k := key(name, labels)
+	h, ok := s.Histograms[k]
+	if !ok {
+		h = newHistogram(name, labels, bounds)
+		s.Histograms[k] = h
+	}
+	h.observe(v)
+}
+
+// Merge adds other's series to s. A histogram whose buckets changed since it
+// was stored restarts with the new buckets.
+func (s *Snapshot) Merge(other *Snapshot) {
+	for k, c := range other.Counters {
+		if existing, ok := s.Counters[k]; ok {
+			existing.Value += c.Value
+		} else {
+			copied := *c
+			s.Counters[k] = &copied
+		}
+	}
+	for k, h := range other.Histograms {
+		existing, ok := s.Histograms[k]
+		if !ok || !sameBounds(existing.Bounds, h.Bounds) {
+			copied := *h
+			copied.Counts = append([]uint64(nil), h.Counts...)
+			s.Histograms[k] = &copied
+			continue
+		}
+		for i, n := range h.Counts {
+			existing.Counts[i] += n
+		}
+		existing.Count += h.Count
+		existing.Sum += h.Sum
+	}
+	if other.UpdatedAt.After(s.UpdatedAt) {
+		s.UpdatedAt = other.UpdatedAt
+	}
+}
+
+func (s *Snapshot) empty() bool {
+	return len(s.Counters) == 0 && len(s.Histograms) == 0
+}
+
+// Load reads a snapshot file. A missing file is an empty snapshot.
+func Load(path string) (*Snapshot, error) {
+	s := NewSnapshot()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metrics: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse metrics %s: %w", path, err)
+	}
+	if s.Counters == nil {
+		s.Counters = make(map[string]*Counter)
+	}
+	if s.Histograms == nil {
+		s.Histograms = make(map[string]*Histogram)
+	}
+	return s, nil
+}
+
+// writeAtomic replaces path with data so readers, including node_exporter,
+// never see a partial file.
+func writeAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Engine is a compiled rule set. It implements handler.Handler and
+// handler.PreToolUseHandler so it can be registered on a Router directly.
+type Engine struct {
+	rules           []*compiledRule
+	defaultDecision types.PermissionDecision
+}
+
+// Result describes the outcome of evaluating a rule set. Decision is empty
+// when no rule matched and the rule set has no default.
+type Result struct {
+	Decision types.PermissionDecision
+	Reason   string
+	Rule     string
+	Matched  []string
+}
+
+var severity = map[types.PermissionDecision]int{
+	types.PermissionAllow: 1,
+	types.PermissionAsk:   2,
+	types.PermissionDeny:  3,
+}
+
+// Evaluate runs every rule that applies to eventName against input. When
+// several rules match, the most restrictive decision wins (deny over ask over
+// allow) and the first matching rule with that decision supplies the reason.
+// The default decision only applies to PreToolUse.
+func (e *Engine) Evaluate(input types.HookInput, eventName types.EventName) (Result, error) {
+	payload, err := toPayload(input)
+	if err != nil {
+		return Result{}, err
+	}
+	toolName, _ := payload["tool_name"].(string)
+
+	var result Result
+	for _, rule := range e.rules {
+		if !rule.applies(eventName, toolName) || !rule.matches(payload) {
+			continue
+		}
+
+		result.Matched = append(result.Matched, rule.Name)
+		if severity[rule.Decision] > severity[result.Decision] {
+			result.Decision = rule.Decision
+			result.Rule = rule.Name
+			result.Reason = rule.Reason
+			if result.Reason == "" {
+				result.Reason = fmt.Sprintf("matched policy rule %q", rule.Name)
+			}
+		}
+	}
+
+	if result.Decision == "" && e.defaultDecision != "" && eventName == types.EventPreToolUse {
+		result.Decision = e.defaultDecision
+		result.Reason = "no policy rule matched"
+	}
+	return result, nil
+}
+
+func (e *Engine) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	result, err := e.Evaluate(input, eventName)
+	if err != nil {
+		return nil, err
+	}
+
+	if eventName == types.EventPreToolUse {
+		if result.Decision == "" {
+			return types.PreToolUseOutput{}, nil
+		}
+		return types.Permission(result.Decision, result.Reason), nil
+	}
+
+	// Other events have no permission prompt, so ask is dropped, and deny
+	// becomes the event's block decision where it has one.
+	if result.Decision != types.PermissionDeny {
+		return types.Success(), nil
+	}
+	switch eventName {
+	case types.EventUserPromptSubmit:
+		return types.UserPromptSubmitOutput{Decision: types.DecisionBlock, Reason: result.Reason}, nil
+	case types.EventPostToolUse:
+		return types.PostToolUseOutput{Decision: types.DecisionBlock, Reason: result.Reason}, nil
+	case types.EventStop:
+		return types.KeepWorking(result.Reason), nil
+	case types.EventSubagentStop:
+		return types.SubagentStopOutput{Decision: types.DecisionBlock, Reason: result.Reason}, nil
+	}
+	return types.Success(), nil
+}
+
+func (e *Engine) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
+	result, err := e.Evaluate(input, types.EventPreToolUse)
+	if err != nil {
+		return types.PreToolUseOutput{}, err
+	}
+	if result.Decision == "" {
+		return types.PreToolUseOutput{}, nil
+	}
+	return types.Permission(result.Decision, result.Reason), nil
+}
+
+func (r *compiledRule) applies(eventName types.EventName, toolName string) bool {
+	if !r.events[eventName] {
+		return false
+	}
+	if len(r.tools) == 0 {
+		return true
+	}
+	for _, pattern := range r.tools {
+		if pattern.Match(toolName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *compiledRule) matches(payload map[string]interface{}) bool {
+	if len(r.conditions) == 0 {
+		return true
+	}
+
+	for _, cond := range r.conditions {
+		ok := cond.evaluate(payload)
+		if r.Match == MatchAny && ok {
+			return true
+		}
+		if r.Match == MatchAll && !ok {
+			return false
+		}
+	}
+	return r.Match == MatchAll
+}
+
+func (c compiledCondition) evaluate(payload map[string]interface{}) bool {
+	values := lookup(payload, c.path)
+
+	var ok bool
+	if c.Exists != nil {
+		ok = (len(values) > 0) == *c.Exists
+	} else {
+		for _, v := range values {
+			if s, isScalar := stringify(v); isScalar && c.match(s) {
+				ok = true
+				break
+			}
+		}
+	}
+
+	if c.Not {
+		return !ok
+	}
+	return ok
+}
+
+func toPayload(input types.HookInput) (map[string]interface{}, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode input for policy evaluation: %w", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode input for policy evaluation: %w", err)
+	}
+	return payload, nil
+}
+
+// lookup returns every value reachable from v along path.
+func lookup(v interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		if v == nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+
+	segment, rest := path[0], path[1:]
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if segment == "*" {
+			var out []interface{}
+			for _, child := range node {
+				out = append(out, lookup(child, rest)...)
+			}
+			return out
+		}
+		child, ok := node[segment]
+		if !ok {
+			return nil
+		}
+		return lookup(child, rest)
+	case []interface{}:
+		if segment == "*" {
+			var out []interface{}
+			for _, child := range node {
+				out = append(out, lookup(child, rest)...)
+			}
+			return out
+		}
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(node) {
+			return nil
+		}
+		return lookup(node[index], rest)
+	default:
+		return nil
+	}
+}
+
+func stringify(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
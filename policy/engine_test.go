@@ -0,0 +1,113 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const testPolicy = `
+default: ask
+rules:
+  - name: no-rm
+    tools: [Bash]
+    conditions:
+      - field: tool_input.command
+        regex: '\brm\b'
+    decision: deny
+    reason: no rm
+  - name: push-but-not-main
+    tools: [Bash]
+    conditions:
+      - field: tool_input.command
+        prefix: git push
+      - field: tool_input.command
+        contains: main
+        not: true
+    decision: allow
+  - name: no-passwords
+    events: [UserPromptSubmit]
+    conditions:
+      - field: prompt
+        contains: password
+    decision: deny
+    reason: prompt contains a password
+  - name: ask-deploy
+    events: [UserPromptSubmit]
+    conditions:
+      - field: prompt
+        contains: deploy
+    decision: ask
+  - name: review-writes
+    events: [PostToolUse]
+    tools: [Write]
+    decision: deny
+    reason: review the write
+  - name: keep-going
+    events: [Stop, SubagentStop]
+    conditions:
+      - field: stop_hook_active
+        equals: "false"
+    decision: deny
+    reason: run the tests
+`
+
+func TestHandleEvent(t *testing.T) {
+	engine, err := Parse([]byte(testPolicy), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bash := func(command string) types.PreToolUseInput {
+		return types.PreToolUseInput{ToolName: types.ToolBash, ToolInput: map[string]interface{}{"command": command}}
+	}
+	tests := []struct {
+		name     string
+		event    types.EventName
+		input    types.HookInput
+		want     string
+		exitCode int
+	}{
+		{"deny rule", types.EventPreToolUse, bash("rm -rf build"),
+			`{"allowTool":false,"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"deny","permissionDecisionReason":"no rm"}}`, types.ExitBlocking},
+		{"not condition holds", types.EventPreToolUse, bash("git push origin dev"),
+			`{"allowTool":true,"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"allow","permissionDecisionReason":"matched policy rule \"push-but-not-main\""}}`, types.ExitSuccess},
+		{"not condition fails, default", types.EventPreToolUse, bash("git push origin main"),
+			`{"hookSpecificOutput":{"hookEventName":"PreToolUse","permissionDecision":"ask","permissionDecisionReason":"no policy rule matched"}}`, types.ExitSuccess},
+		{"prompt denied", types.EventUserPromptSubmit, types.UserPromptSubmitInput{Prompt: "my password is hunter2"},
+			`{"decision":"block","reason":"prompt contains a password"}`, types.ExitSuccess},
+		{"prompt ask dropped", types.EventUserPromptSubmit, types.UserPromptSubmitInput{Prompt: "deploy it"},
+			`{}`, types.ExitSuccess},
+		{"prompt without default", types.EventUserPromptSubmit, types.UserPromptSubmitInput{Prompt: "hello"},
+			`{}`, types.ExitSuccess},
+		{"post tool use denied", types.EventPostToolUse, types.PostToolUseInput{ToolName: types.ToolWrite},
+			`{"decision":"block","reason":"review the write"}`, types.ExitSuccess},
+		{"post tool use without default", types.EventPostToolUse, types.PostToolUseInput{ToolName: types.ToolRead},
+			`{}`, types.ExitSuccess},
+		{"stop denied", types.EventStop, types.StopInput{},
+			`{"decision":"block","reason":"run the tests"}`, types.ExitSuccess},
+		{"stop hook active", types.EventStop, types.StopInput{StopHookActive: true},
+			`{}`, types.ExitSuccess},
+		{"subagent stop denied", types.EventSubagentStop, types.SubagentStopInput{},
+			`{"decision":"block","reason":"run the tests"}`, types.ExitSuccess},
+		{"notification without default", types.EventNotification, types.NotificationInput{},
+			`{}`, types.ExitSuccess},
+	}
+	for _, tt := range tests {
+		output, err := engine.HandleEvent(tt.input, tt.event)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got, err := json.Marshal(output)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: output %s, want %s", tt.name, got, tt.want)
+		}
+		if code := output.ExitWith(); code != tt.exitCode {
+			t.Errorf("%s: exit code %d, want %d", tt.name, code, tt.exitCode)
+		}
+	}
+}
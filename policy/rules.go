@@ -0,0 +1,261 @@
+// Package policy evaluates declarative allow/deny/ask rules loaded from a
+// YAML or JSON file against hook inputs.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/glob"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/shell"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// File is the on-disk representation of a rule set.
+//
+//	default: allow
+//	rules:
+//	  - name: no-recursive-rm
+//	    tools: [Bash]
+//	    conditions:
+//	      - field: tool_input.command
+//	        regex: '\brm\s+-[a-zA-Z]*r'
+//	    decision: deny
+//	    reason: Recursive deletes are not allowed
+type File struct {
+	Default types.PermissionDecision `json:"default,omitempty" yaml:"default,omitempty"`
+	Rules   []Rule                   `json:"rules" yaml:"rules"`
+}
+
+type Rule struct {
+	Name        string                   `json:"name" yaml:"name"`
+	Description string                   `json:"description,omitempty" yaml:"description,omitempty"`
+	Events      []types.EventName        `json:"events,omitempty" yaml:"events,omitempty"`
+	Tools       []string                 `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Match       MatchMode                `json:"match,omitempty" yaml:"match,omitempty"`
+	Conditions  []Condition              `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+	Decision    types.PermissionDecision `json:"decision" yaml:"decision"`
+	Reason      string                   `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// MatchMode controls how a rule combines its conditions.
+type MatchMode string
+
+const (
+	MatchAll MatchMode = "all"
+	MatchAny MatchMode = "any"
+)
+
+// Condition tests the value found at Field, a dotted path into the event
+// payload such as "tool_input.command" or "prompt". A "*" segment matches
+// every element of an array or object. Exactly one operator must be set.
+type Condition struct {
+	Field    string `json:"field" yaml:"field"`
+	Equals   string `json:"equals,omitempty" yaml:"equals,omitempty"`
+	Contains string `json:"contains,omitempty" yaml:"contains,omitempty"`
+	Prefix   string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Suffix   string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+	Regex    string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Glob     string `json:"glob,omitempty" yaml:"glob,omitempty"`
+	Exists   *bool  `json:"exists,omitempty" yaml:"exists,omitempty"`
+	Not      bool   `json:"not,omitempty" yaml:"not,omitempty"`
+
+	// Invokes parses the value as a shell command line and matches when any
+	// command in it, including ones nested in pipelines, substitutions,
+	// "sh -c" or behind wrappers like sudo, runs one of the executables.
+	Invokes []string `json:"invokes,omitempty" yaml:"invokes,omitempty"`
+}
+
+// LoadFile reads and compiles a rule set. Files ending in .yaml or .yml are
+// decoded as YAML, everything else as JSON.
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	format := FormatJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = FormatYAML
+	}
+
+	engine, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return engine, nil
+}
+
+type Format int
+
+const (
+	FormatJSON Format = iota
+	FormatYAML
+)
+
+func Parse(data []byte, format Format) (*Engine, error) {
+	var file File
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to parse policy: %w", err)
+		}
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("failed to parse policy: %w", err)
+		}
+	}
+	return Compile(file)
+}
+
+// Compile validates a rule set and prepares it for evaluation.
+func Compile(file File) (*Engine, error) {
+	if file.Default != "" && !file.Default.IsValid() {
+		return nil, fmt.Errorf("invalid default decision %q", file.Default)
+	}
+
+	engine := &Engine{defaultDecision: file.Default}
+	for i, rule := range file.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+type compiledRule struct {
+	Rule
+	events     map[types.EventName]bool
+	tools      []*glob.Pattern
+	conditions []compiledCondition
+}
+
+type compiledCondition struct {
+	Condition
+	path  []string
+	match func(string) bool
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	if !rule.Decision.IsValid() {
+		return nil, fmt.Errorf("invalid decision %q", rule.Decision)
+	}
+	switch rule.Match {
+	case "":
+		rule.Match = MatchAll
+	case MatchAll, MatchAny:
+	default:
+		return nil, fmt.Errorf("invalid match mode %q", rule.Match)
+	}
+
+	compiled := &compiledRule{Rule: rule, events: make(map[types.EventName]bool)}
+
+	events := rule.Events
+	if len(events) == 0 {
+		events = []types.EventName{types.EventPreToolUse}
+	}
+	for _, eventName := range events {
+		if !eventName.IsValid() {
+			return nil, &types.InvalidEventError{EventName: string(eventName)}
+		}
+		compiled.events[eventName] = true
+	}
+
+	for _, tool := range rule.Tools {
+		pattern, err := glob.Compile(tool)
+		if err != nil {
+			return nil, err
+		}
+		compiled.tools = append(compiled.tools, pattern)
+	}
+
+	for _, cond := range rule.Conditions {
+		c, err := compileCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		compiled.conditions = append(compiled.conditions, c)
+	}
+
+	return compiled, nil
+}
+
+func compileCondition(cond Condition) (compiledCondition, error) {
+	if cond.Field == "" {
+		return compiledCondition{}, fmt.Errorf("condition is missing a field")
+	}
+
+	c := compiledCondition{Condition: cond, path: strings.Split(cond.Field, ".")}
+	operators := 0
+
+	if cond.Equals != "" {
+		operators++
+		c.match = func(v string) bool { return v == cond.Equals }
+	}
+	if cond.Contains != "" {
+		operators++
+		c.match = func(v string) bool { return strings.Contains(v, cond.Contains) }
+	}
+	if cond.Prefix != "" {
+		operators++
+		c.match = func(v string) bool { return strings.HasPrefix(v, cond.Prefix) }
+	}
+	if cond.Suffix != "" {
+		operators++
+		c.match = func(v string) bool { return strings.HasSuffix(v, cond.Suffix) }
+	}
+	if cond.Regex != "" {
+		operators++
+		re, err := regexp.Compile(cond.Regex)
+		if err != nil {
+			return c, fmt.Errorf("field %s: invalid regex: %w", cond.Field, err)
+		}
+		c.match = re.MatchString
+	}
+	if cond.Glob != "" {
+		operators++
+		pattern, err := glob.Compile(cond.Glob)
+		if err != nil {
+			return c, fmt.Errorf("field %s: %w", cond.Field, err)
+		}
+		c.match = pattern.Match
+	}
+	if cond.Exists != nil {
+		operators++
+	}
+	if len(cond.Invokes) > 0 {
+		operators++
+		c.match = func(v string) bool {
+			script, err := shell.Parse(v)
+			if err != nil {
+				// Unparseable commands cannot be analyzed; treat them as a
+				// match so restrictive rules fail closed.
+				return true
+			}
+			return len(script.Invokes(cond.Invokes...)) > 0
+		}
+	}
+
+	if operators != 1 {
+		return c, fmt.Errorf("field %s: condition must have exactly one operator", cond.Field)
+	}
+	return c, nil
+}
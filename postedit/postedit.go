@@ -0,0 +1,156 @@
+// Package postedit runs formatters and linters on the file the model just
+// changed with Write, Edit or MultiEdit. Formatters run first and stay
+// silent when they succeed; linter failures are fed back to the model
+// through the PostToolUse block decision, so it fixes them in its next
+// step.
+package postedit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/glob"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/verify"
+)
+
+// DefaultOutputLines is how many trailing output lines of a failed tool the
+// feedback shows.
+const DefaultOutputLines = verify.DefaultOutputLines
+
+// Runner is a PostToolUse handler running the tools matching each edited
+// file, formatters in order and then linters in order:
+//
+//	runner, err := postedit.New(
+//	    postedit.Format("gofmt -w {file}").ForFiles("*.go"),
+//	    postedit.Lint("go vet {dir}").ForFiles("*.go"),
+//	    postedit.Lint("npx eslint {file}").ForFiles("*.{js,ts,tsx}"),
+//	)
+//	router := handler.NewRouter().On(types.EventPostToolUse, runner)
+type Runner struct {
+	tools       []compiledTool
+	outputLines int
+}
+
+type compiledTool struct {
+	Tool
+	files []*glob.Pattern
+}
+
+func New(tools ...Tool) (*Runner, error) {
+	r := &Runner{outputLines: DefaultOutputLines}
+	for _, tool := range tools {
+		if tool.Name == "" {
+			tool.Name = tool.Command
+		}
+		if err := tool.validate(); err != nil {
+			return nil, err
+		}
+		compiled := compiledTool{Tool: tool}
+		for _, pattern := range tool.Files {
+			p, err := glob.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tool %q: %w", tool.Name, err)
+			}
+			compiled.files = append(compiled.files, p)
+		}
+		r.tools = append(r.tools, compiled)
+	}
+	return r, nil
+}
+
+func (r *Runner) WithOutputLines(n int) *Runner {
+	r.outputLines = n
+	return r
+}
+
+func (r *Runner) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	if in, ok := input.(types.PostToolUseInput); ok {
+		return r.HandlePostToolUse(in)
+	}
+	return types.Success(), nil
+}
+
+func (r *Runner) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
+	file, ok := editedFile(input)
+	if !ok {
+		return types.PostToolUseOutput{}, nil
+	}
+	if _, err := os.Stat(file); err != nil {
+		return types.PostToolUseOutput{}, nil
+	}
+	rel := relative(input.CWD, file)
+
+	logger := input.Logger()
+	var failures []string
+	for _, kind := range []Kind{KindFormat, KindLint} {
+		for _, tool := range r.tools {
+			if tool.Kind != kind || !tool.matches(rel) {
+				continue
+			}
+			check := verify.Check{Name: tool.Name, Command: tool.expand(file), Timeout: tool.Timeout}
+			if check.Timeout <= 0 {
+				check.Timeout = DefaultTimeout
+			}
+			result := check.Run(input.Context(), input.CWD)
+			logger.Debug("post-edit tool finished", "tool", tool.Name, "kind", tool.Kind,
+				"file", rel, "exit_code", result.ExitCode, "duration", result.Duration)
+			if !result.Passed() {
+				failures = append(failures, result.Summary(r.outputLines))
+			}
+		}
+	}
+	if len(failures) == 0 {
+		return types.PostToolUseOutput{}, nil
+	}
+	return types.PostToolUseOutput{
+		Decision: types.DecisionBlock,
+		Reason:   fmt.Sprintf("Checks failed for %s:\n\n%s", rel, strings.Join(failures, "\n\n")),
+	}, nil
+}
+
+// editedFile returns the absolute path of the file a Write, Edit or
+// MultiEdit call changed.
+func editedFile(input types.PostToolUseInput) (string, bool) {
+	switch input.ToolName {
+	case types.ToolWrite, types.ToolEdit, types.ToolMultiEdit:
+	default:
+		return "", false
+	}
+	file, _ := input.ToolInput["file_path"].(string)
+	if file == "" {
+		return "", false
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(input.CWD, file)
+	}
+	return filepath.Clean(file), true
+}
+
+// relative returns file relative to cwd, or file itself if it lies
+// outside.
+func relative(cwd, file string) string {
+	if cwd == "" {
+		return file
+	}
+	rel, err := filepath.Rel(cwd, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return rel
+}
+
+func (t compiledTool) matches(rel string) bool {
+	for i, p := range t.files {
+		name := filepath.Base(rel)
+		if strings.Contains(t.Files[i], "/") {
+			name = filepath.ToSlash(rel)
+		}
+		if p.Match(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package postedit
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Kind tells formatters from linters.
+type Kind string
+
+const (
+	// KindFormat rewrites the file. It runs before any linter and stays
+	// silent unless it fails, which usually means the file does not parse.
+	KindFormat Kind = "format"
+	// KindLint reports problems. Its failures are fed back to the model.
+	KindLint Kind = "lint"
+)
+
+// DefaultTimeout bounds a tool that sets no timeout of its own.
+const DefaultTimeout = 30 * time.Second
+
+// Tool is a formatter or linter for the files matching Files, glob
+// patterns matched against the file's base name, or against its path
+// relative to the working directory when they contain a "/".
+//
+// Command runs with sh -c in the working directory. "{file}" in it is
+// replaced by the quoted path of the edited file and "{dir}" by the quoted
+// path of its directory; without either, the path is appended.
+type Tool struct {
+	Name    string        `json:"name,omitempty" yaml:"name,omitempty"`
+	Kind    Kind          `json:"kind" yaml:"kind"`
+	Files   []string      `json:"files" yaml:"files"`
+	Command string        `json:"command" yaml:"command"`
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Format returns a formatter running command.
+func Format(command string) Tool {
+	return Tool{Kind: KindFormat, Command: command}
+}
+
+// Lint returns a linter running command.
+func Lint(command string) Tool {
+	return Tool{Kind: KindLint, Command: command}
+}
+
+func (t Tool) ForFiles(patterns ...string) Tool {
+	t.Files = append(append([]string(nil), t.Files...), patterns...)
+	return t
+}
+
+func (t Tool) Named(name string) Tool {
+	t.Name = name
+	return t
+}
+
+func (t Tool) WithTimeout(timeout time.Duration) Tool {
+	t.Timeout = timeout
+	return t
+}
+
+func (t Tool) validate() error {
+	if strings.TrimSpace(t.Command) == "" {
+		return fmt.Errorf("tool %q is missing a command", t.Name)
+	}
+	if t.Kind != KindFormat && t.Kind != KindLint {
+		return fmt.Errorf("tool %q: invalid kind %q", t.Name, t.Kind)
+	}
+	if len(t.Files) == 0 {
+		return fmt.Errorf("tool %q matches no files", t.Name)
+	}
+	return nil
+}
+
+// expand substitutes the edited file into the command.
+func (t Tool) expand(file string) string {
+	if !strings.Contains(t.Command, "{file}") && !strings.Contains(t.Command, "{dir}") {
+		return t.Command + " " + quote(file)
+	}
+	return strings.NewReplacer(
+		"{file}", quote(file),
+		"{dir}", quote(filepath.Dir(file)),
+	).Replace(t.Command)
+}
+
+// quote quotes s for sh.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,152 @@
+// Package ratelimit caps how often tools may be called within a session,
+// for example "no more than 20 WebFetch calls per session" or "at most 5
+// Bash commands per minute". Counters live in the session state, so limits
+// hold across hook processes.
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+type Strategy string
+
+const (
+	// StrategyFixedWindow allows Limit calls per Window, counted from the
+	// first call of the window. A zero Window spans the whole session.
+	StrategyFixedWindow Strategy = "fixed_window"
+	// StrategyTokenBucket allows bursts of up to Limit calls and refills
+	// Limit tokens evenly over each Window.
+	StrategyTokenBucket Strategy = "token_bucket"
+)
+
+// Scope selects what a limit counts separately within a session.
+type Scope string
+
+const (
+	// ScopeSession shares one counter between all matching tools.
+	ScopeSession Scope = "session"
+	// ScopeTool keeps a counter per tool name.
+	ScopeTool Scope = "tool"
+	// ScopeMCPServer keeps a counter per MCP server. Such limits only apply
+	// to MCP tools, named mcp__<server>__<tool>.
+	ScopeMCPServer Scope = "mcp_server"
+)
+
+// Limit is one rate limit. Tools are glob patterns matched against the tool
+// name; an empty list matches every tool.
+type Limit struct {
+	Name     string                   `json:"name" yaml:"name"`
+	Tools    []string                 `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Scope    Scope                    `json:"scope,omitempty" yaml:"scope,omitempty"`
+	Strategy Strategy                 `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	Limit    int                      `json:"limit" yaml:"limit"`
+	Window   time.Duration            `json:"window,omitempty" yaml:"window,omitempty"`
+	Decision types.PermissionDecision `json:"decision,omitempty" yaml:"decision,omitempty"`
+}
+
+// FixedWindow returns a limit of n calls per window, or per session if
+// window is zero.
+func FixedWindow(name string, n int, window time.Duration) Limit {
+	return Limit{Name: name, Strategy: StrategyFixedWindow, Limit: n, Window: window}
+}
+
+// TokenBucket returns a limit allowing bursts of n calls, refilled at n calls
+// per window.
+func TokenBucket(name string, n int, window time.Duration) Limit {
+	return Limit{Name: name, Strategy: StrategyTokenBucket, Limit: n, Window: window}
+}
+
+func (l Limit) ForTools(patterns ...string) Limit {
+	l.Tools = append(append([]string(nil), l.Tools...), patterns...)
+	return l
+}
+
+func (l Limit) By(scope Scope) Limit {
+	l.Scope = scope
+	return l
+}
+
+// WithDecision sets the decision once the limit is exhausted, deny (the
+// default) or ask.
+func (l Limit) WithDecision(decision types.PermissionDecision) Limit {
+	l.Decision = decision
+	return l
+}
+
+func (l Limit) withDefaults() Limit {
+	if l.Scope == "" {
+		l.Scope = ScopeSession
+	}
+	if l.Strategy == "" {
+		l.Strategy = StrategyFixedWindow
+	}
+	if l.Decision == "" {
+		l.Decision = types.PermissionDeny
+	}
+	return l
+}
+
+func (l Limit) validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("rate limit is missing a name")
+	}
+	if strings.Contains(l.Name, "/") {
+		return fmt.Errorf("rate limit %q: name must not contain '/'", l.Name)
+	}
+	if l.Limit <= 0 {
+		return fmt.Errorf("rate limit %q: limit must be positive", l.Name)
+	}
+	if l.Window < 0 {
+		return fmt.Errorf("rate limit %q: window must not be negative", l.Name)
+	}
+	switch l.Strategy {
+	case StrategyFixedWindow:
+	case StrategyTokenBucket:
+		if l.Window == 0 {
+			return fmt.Errorf("rate limit %q: token bucket needs a window", l.Name)
+		}
+	default:
+		return fmt.Errorf("rate limit %q: invalid strategy %q", l.Name, l.Strategy)
+	}
+	switch l.Scope {
+	case ScopeSession, ScopeTool, ScopeMCPServer:
+	default:
+		return fmt.Errorf("rate limit %q: invalid scope %q", l.Name, l.Scope)
+	}
+	if l.Decision != types.PermissionDeny && l.Decision != types.PermissionAsk {
+		return fmt.Errorf("rate limit %q: decision must be deny or ask, got %q", l.Name, l.Decision)
+	}
+	return nil
+}
+
+// per describes the window for reasons, e.g. "per minute" or "per session".
+func (l Limit) per() string {
+	switch l.Window {
+	case 0:
+		return "per session"
+	case time.Second:
+		return "per second"
+	case time.Minute:
+		return "per minute"
+	case time.Hour:
+		return "per hour"
+	}
+	return "per " + l.Window.String()
+}
+
+// MCPServer returns the server of an MCP tool name, or "" for other tools.
+func MCPServer(toolName types.ToolName) string {
+	rest, ok := strings.CutPrefix(string(toolName), "mcp__")
+	if !ok {
+		return ""
+	}
+	server, _, ok := strings.Cut(rest, "__")
+	if !ok {
+		return ""
+	}
+	return server
+}
@@ -0,0 +1,347 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/callkey"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/glob"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const (
+	keyPrefix     = "ratelimit/"
+	pendingPrefix = "ratelimit-pending/"
+)
+
+// PendingTTL is how long a call allowed at PreToolUse holds its place in
+// the limits while waiting for its PostToolUse.
+var PendingTTL = 15 * time.Minute
+
+// Limiter enforces a set of limits on PreToolUse. It implements
+// handler.Handler, handler.PreToolUseHandler and handler.Observer.
+//
+// A call that every limit it matches allows is reserved against them at
+// PreToolUse and only counted when its PostToolUse arrives, so calls that
+// another handler denies or the user rejects do not use up the quota.
+// Register the limiter for both events, and as an observer so that a
+// reservation is released as soon as the resolved decision blocks the call.
+// Reservations that are never confirmed expire after PendingTTL.
+type Limiter struct {
+	limits []compiledLimit
+	now    func() time.Time
+}
+
+type compiledLimit struct {
+	Limit
+	tools []*glob.Pattern
+}
+
+func NewLimiter(limits ...Limit) (*Limiter, error) {
+	l := &Limiter{now: time.Now}
+	for _, limit := range limits {
+		limit = limit.withDefaults()
+		if err := limit.validate(); err != nil {
+			return nil, err
+		}
+		compiled := compiledLimit{Limit: limit}
+		for _, tool := range limit.Tools {
+			pattern, err := glob.Compile(tool)
+			if err != nil {
+				return nil, fmt.Errorf("rate limit %q: %w", limit.Name, err)
+			}
+			compiled.tools = append(compiled.tools, pattern)
+		}
+		l.limits = append(l.limits, compiled)
+	}
+	return l, nil
+}
+
+// Status is the state of one limit for one call.
+type Status struct {
+	Limit     Limit
+	Key       string
+	Allowed   bool
+	Remaining int
+	// ResetAt is when the next call becomes available, or zero for
+	// per-session windows that never reset.
+	ResetAt time.Time
+}
+
+// Reason describes an exhausted limit, including when it resets.
+func (s Status) Reason(toolName types.ToolName) string {
+	reason := fmt.Sprintf("Rate limit %q reached for %s: %d of %d calls left %s",
+		s.Limit.Name, toolName, s.Remaining, s.Limit.Limit, s.Limit.per())
+	if s.ResetAt.IsZero() {
+		return reason + "; the limit does not reset during this session"
+	}
+	wait := time.Until(s.ResetAt).Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return reason + fmt.Sprintf("; next call available in %s (at %s)", wait, s.ResetAt.Format(time.Kitchen))
+}
+
+func (l *Limiter) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		return l.HandlePreToolUse(in)
+	case types.PostToolUseInput:
+		return l.HandlePostToolUse(in)
+	}
+	return types.Success(), nil
+}
+
+// HandlePostToolUse counts the call that just ran against its limits.
+func (l *Limiter) HandlePostToolUse(input types.PostToolUseInput) (types.PostToolUseOutput, error) {
+	if err := l.Commit(input); err != nil {
+		return types.PostToolUseOutput{}, err
+	}
+	return types.PostToolUseOutput{}, nil
+}
+
+// Observe releases the reservation of a call whose resolved PreToolUse
+// output denies or blocks it.
+func (l *Limiter) Observe(inv handler.Invocation) {
+	in, ok := inv.Input.(types.PreToolUseInput)
+	if !ok || inv.Err != nil {
+		return
+	}
+	if decision, _ := handler.Outcome(inv.Output, nil); decision != string(types.PermissionDeny) && decision != handler.OutcomeBlock {
+		return
+	}
+	key := pendingPrefix + callkey.Key(string(in.ToolName), in.ToolUseID, in.ToolInput)
+	if err := state.Update(in.Context(), func(tx *state.Tx) error {
+		tx.Delete(key)
+		return nil
+	}); err != nil {
+		in.Logger().Warn("rate limit reservation not released", "error", err)
+	}
+}
+
+func (l *Limiter) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
+	statuses, err := l.Take(input)
+	if err != nil {
+		return types.PreToolUseOutput{}, err
+	}
+	var exceeded *Status
+	for i, s := range statuses {
+		if s.Allowed {
+			continue
+		}
+		if exceeded == nil || (s.Limit.Decision == types.PermissionDeny && exceeded.Limit.Decision != types.PermissionDeny) {
+			exceeded = &statuses[i]
+		}
+	}
+	if exceeded == nil {
+		return types.PreToolUseOutput{}, nil
+	}
+	return types.Permission(exceeded.Limit.Decision, exceeded.Reason(input.ToolName)), nil
+}
+
+// Take checks every limit that applies to the call, counting the calls
+// still reserved against it, and, if none is exhausted, reserves the call
+// against all of them until Commit or PendingTTL. It returns the status of
+// each applicable limit.
+func (l *Limiter) Take(input types.PreToolUseInput) ([]Status, error) {
+	var statuses []Status
+	err := state.Update(input.Context(), func(tx *state.Tx) error {
+		statuses = statuses[:0]
+		now := l.now()
+		pending := pendingPrefix + callkey.Key(string(input.ToolName), input.ToolUseID, input.ToolInput)
+		held := reserved(tx, pending)
+		allowed := true
+		var keys []string
+		for _, limit := range l.limits {
+			key, ok := limit.key(input.ToolName)
+			if !ok {
+				continue
+			}
+			c, _, err := state.Lookup[counter](tx, key)
+			if err != nil {
+				return err
+			}
+			c = c.advance(limit.Limit, now)
+			for range held[key] {
+				c = c.take(limit.Limit)
+			}
+			status := c.status(limit.Limit, now)
+			status.Key = key
+			allowed = allowed && status.Allowed
+			statuses = append(statuses, status)
+			keys = append(keys, key)
+		}
+		if !allowed || len(keys) == 0 {
+			return nil
+		}
+		for i := range statuses {
+			statuses[i].Remaining = max(statuses[i].Remaining-1, 0)
+		}
+		return tx.SetWithTTL(pending, keys, PendingTTL)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+	return statuses, nil
+}
+
+// Commit counts a call that ran against the limits it was reserved
+// against at PreToolUse, or, if it has no reservation, against every limit
+// that applies to it.
+func (l *Limiter) Commit(input types.PostToolUseInput) error {
+	err := state.Update(input.Context(), func(tx *state.Tx) error {
+		now := l.now()
+		pending := pendingPrefix + callkey.Key(string(input.ToolName), input.ToolUseID, input.ToolInput)
+		keys, found, err := state.Lookup[[]string](tx, pending)
+		if err != nil {
+			return err
+		}
+		tx.Delete(pending)
+		for _, limit := range l.limits {
+			key, ok := limit.key(input.ToolName)
+			if !ok || (found && !slices.Contains(keys, key)) {
+				continue
+			}
+			c, _, err := state.Lookup[counter](tx, key)
+			if err != nil {
+				return err
+			}
+			c = c.advance(limit.Limit, now).take(limit.Limit)
+			if err := tx.SetWithTTL(key, c, c.ttl(limit.Limit, now)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rate limit: %w", err)
+	}
+	return nil
+}
+
+// reserved returns the calls other than self reserved against each limit
+// key.
+func reserved(tx *state.Tx, self string) map[string][]string {
+	held := make(map[string][]string)
+	for _, pending := range tx.Keys(pendingPrefix) {
+		if pending == self {
+			continue
+		}
+		keys, _, _ := state.Lookup[[]string](tx, pending)
+		for _, key := range keys {
+			held[key] = append(held[key], pending)
+		}
+	}
+	return held
+}
+
+func (c compiledLimit) key(toolName types.ToolName) (string, bool) {
+	if len(c.tools) > 0 {
+		matched := false
+		for _, pattern := range c.tools {
+			if pattern.Match(string(toolName)) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", false
+		}
+	}
+	key := keyPrefix + c.Name
+	switch c.Scope {
+	case ScopeTool:
+		key += "/tool/" + string(toolName)
+	case ScopeMCPServer:
+		server := MCPServer(toolName)
+		if server == "" {
+			return "", false
+		}
+		key += "/mcp/" + server
+	}
+	return key, true
+}
+
+// counter is the persisted state of one limit. For fixed windows Used
+// counts calls since Start; for token buckets Tokens holds the bucket level
+// as of Start.
+type counter struct {
+	Start  time.Time `json:"start"`
+	Used   int       `json:"used,omitempty"`
+	Tokens float64   `json:"tokens,omitempty"`
+}
+
+// advance brings the counter up to now: a fixed window that has elapsed is
+// restarted and a bucket is refilled.
+func (c counter) advance(limit Limit, now time.Time) counter {
+	switch limit.Strategy {
+	case StrategyTokenBucket:
+		if c.Start.IsZero() {
+			return counter{Start: now, Tokens: float64(limit.Limit)}
+		}
+		elapsed := now.Sub(c.Start)
+		if elapsed > 0 {
+			c.Tokens = math.Min(float64(limit.Limit), c.Tokens+elapsed.Seconds()*limit.rate())
+			c.Start = now
+		}
+	default:
+		if c.Start.IsZero() || (limit.Window > 0 && !now.Before(c.Start.Add(limit.Window))) {
+			return counter{Start: now}
+		}
+	}
+	return c
+}
+
+func (c counter) status(limit Limit, now time.Time) Status {
+	s := Status{Limit: limit, Remaining: c.remaining(limit)}
+	switch limit.Strategy {
+	case StrategyTokenBucket:
+		s.Allowed = c.Tokens >= 1
+		if !s.Allowed {
+			wait := time.Duration((1 - c.Tokens) / limit.rate() * float64(time.Second))
+			s.ResetAt = now.Add(wait)
+		}
+	default:
+		s.Allowed = c.Used < limit.Limit
+		if limit.Window > 0 {
+			s.ResetAt = c.Start.Add(limit.Window)
+		}
+	}
+	return s
+}
+
+func (c counter) take(limit Limit) counter {
+	if limit.Strategy == StrategyTokenBucket {
+		c.Tokens--
+	} else {
+		c.Used++
+	}
+	return c
+}
+
+func (c counter) remaining(limit Limit) int {
+	if limit.Strategy == StrategyTokenBucket {
+		return int(math.Floor(c.Tokens))
+	}
+	return max(limit.Limit-c.Used, 0)
+}
+
+// ttl keeps the counter until it would have reset anyway.
+func (c counter) ttl(limit Limit, now time.Time) time.Duration {
+	if limit.Window == 0 {
+		return 0
+	}
+	if limit.Strategy == StrategyTokenBucket {
+		return time.Duration((float64(limit.Limit) - c.Tokens) / limit.rate() * float64(time.Second))
+	}
+	return c.Start.Add(limit.Window).Sub(now)
+}
+
+// rate is the token bucket refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Limit) / l.Window.Seconds()
+}
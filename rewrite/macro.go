@@ -0,0 +1,127 @@
+package rewrite
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const (
+	// DefaultMacroPrefix starts a macro at the beginning of a line.
+	DefaultMacroPrefix = "/"
+	// DefaultMacroDir holds the project's templates, relative to the
+	// session's working directory.
+	DefaultMacroDir = ".claude/prompts"
+)
+
+// macroName keeps macro names from reaching outside the template
+// directories.
+var macroName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// MacroExpander replaces each line of the prompt that starts with the
+// prefix and the name of a template, such as "/review focus on errors",
+// with the template <dir>/<name>.md. Lines naming no template are left
+// alone, so the host's own slash commands still work.
+//
+// In the template, "${args}" is replaced by the rest of the line and
+// "${1}", "${2}"... by its words; when the template uses none of them, the
+// arguments are appended to it. Other variables are left for a Variables
+// rewriter later in the chain. Expanded templates are not searched for
+// further macros.
+type MacroExpander struct {
+	dirs   []string
+	prefix string
+}
+
+// Macros returns a MacroExpander reading templates from dirs, searched in
+// order. Relative directories are resolved against the session's working
+// directory and "~/" against the home directory. Without dirs it reads
+// .claude/prompts, then ~/.claude/prompts.
+func Macros(dirs ...string) *MacroExpander {
+	if len(dirs) == 0 {
+		dirs = []string{DefaultMacroDir, "~/" + DefaultMacroDir}
+	}
+	return &MacroExpander{dirs: dirs, prefix: DefaultMacroPrefix}
+}
+
+func (m *MacroExpander) WithPrefix(prefix string) *MacroExpander {
+	m.prefix = prefix
+	return m
+}
+
+func (m *MacroExpander) Rewrite(input types.UserPromptSubmitInput, prompt string) (string, error) {
+	lines := strings.Split(prompt, "\n")
+	for i, line := range lines {
+		rest, ok := strings.CutPrefix(strings.TrimLeft(line, " \t"), m.prefix)
+		if !ok {
+			continue
+		}
+		name, args, _ := strings.Cut(rest, " ")
+		if !macroName.MatchString(name) {
+			continue
+		}
+		template, found, err := m.template(input.CWD, name)
+		if err != nil {
+			return prompt, err
+		}
+		if found {
+			lines[i] = expandArgs(template, strings.TrimSpace(args))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// template reads the template of the macro name from the first directory
+// holding one.
+func (m *MacroExpander) template(cwd, name string) (string, bool, error) {
+	for _, dir := range m.dirs {
+		if dir == "~" || strings.HasPrefix(dir, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				continue
+			}
+			dir = filepath.Join(home, dir[1:])
+		} else if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cwd, dir)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name+".md"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimRight(string(data), "\n"), true, nil
+	}
+	return "", false, nil
+}
+
+// expandArgs substitutes the arguments of a macro into its template.
+func expandArgs(template, args string) string {
+	words := strings.Fields(args)
+	used := false
+	expanded := expand(template, func(name string) (string, bool) {
+		if name == "args" {
+			used = true
+			return args, true
+		}
+		n, err := strconv.Atoi(name)
+		if err != nil || n < 1 {
+			return "", false
+		}
+		used = true
+		if n > len(words) {
+			return "", true
+		}
+		return words[n-1], true
+	}, false)
+	if !used && args != "" {
+		expanded += "\n\n" + args
+	}
+	return expanded
+}
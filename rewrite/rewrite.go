@@ -0,0 +1,119 @@
+// Package rewrite transforms the prompt the user submits before the model
+// sees it. A Chain runs rewriters in order, each receiving the prompt as the
+// previous one left it, and returns the result as additionalContext of a
+// UserPromptSubmit output, which the host adds next to the prompt as typed.
+// The built-in rewriters expand macros from a template directory and
+// substitute variables from the session state and the environment.
+//
+// The output also has a modifiedPrompt field, but the host does not
+// replace the prompt with it yet. Chain.WithModifiedPrompt uses it anyway,
+// for hosts that do.
+//
+// Each rewrite is noted on the invocation under NoteKey, and the audit log
+// records the original and the rewritten prompt from that note.
+package rewrite
+
+import (
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/safecall"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Rewriter returns prompt rewritten, or prompt itself to leave it alone.
+// input.Prompt is the prompt as the user submitted it.
+type Rewriter interface {
+	Rewrite(input types.UserPromptSubmitInput, prompt string) (string, error)
+}
+
+type RewriterFunc func(input types.UserPromptSubmitInput, prompt string) (string, error)
+
+func (f RewriterFunc) Rewrite(input types.UserPromptSubmitInput, prompt string) (string, error) {
+	return f(input, prompt)
+}
+
+// Chain is a UserPromptSubmit handler running its rewriters in the order
+// they were added:
+//
+//	chain := rewrite.New().
+//	    Add("macros", rewrite.Macros()).
+//	    Add("variables", rewrite.Variables("USER", "JIRA_PROJECT"))
+//	router := handler.NewRouter().On(types.EventUserPromptSubmit, chain)
+//
+// A rewriter that fails is logged and skipped, leaving the prompt as it
+// was before it.
+type Chain struct {
+	rewriters      []named
+	modifiedPrompt bool
+}
+
+// NoteKey is the handler.Note key under which a Chain records a Rewrite.
+const NoteKey = "rewrite.prompt"
+
+// Rewrite is the note a Chain records when it changes the prompt.
+type Rewrite struct {
+	Original  string
+	Rewritten string
+}
+
+// contextHeader introduces the rewritten prompt sent as additionalContext.
+const contextHeader = "The user's prompt expands to the following. Act on this version rather than the prompt as typed:\n\n"
+
+type named struct {
+	name     string
+	rewriter Rewriter
+}
+
+func New() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) Add(name string, r Rewriter) *Chain {
+	c.rewriters = append(c.rewriters, named{name: name, rewriter: r})
+	return c
+}
+
+// WithModifiedPrompt makes the chain return the rewritten prompt as
+// modifiedPrompt instead of additionalContext.
+func (c *Chain) WithModifiedPrompt() *Chain {
+	c.modifiedPrompt = true
+	return c
+}
+
+func (c *Chain) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	if in, ok := input.(types.UserPromptSubmitInput); ok {
+		return c.HandleUserPromptSubmit(in)
+	}
+	return types.Success(), nil
+}
+
+func (c *Chain) HandleUserPromptSubmit(input types.UserPromptSubmitInput) (types.UserPromptSubmitOutput, error) {
+	prompt := c.Rewrite(input)
+	if prompt == input.Prompt {
+		return types.UserPromptSubmitOutput{}, nil
+	}
+	handler.Note(input.Context(), NoteKey, Rewrite{Original: input.Prompt, Rewritten: prompt})
+	if c.modifiedPrompt {
+		return types.UserPromptSubmitOutput{ModifiedPrompt: &prompt}, nil
+	}
+	return types.UserPromptSubmitOutput{}.WithAdditionalContext(contextHeader + prompt), nil
+}
+
+// Rewrite runs the rewriters on the input's prompt and returns the result.
+func (c *Chain) Rewrite(input types.UserPromptSubmitInput) string {
+	logger := input.Logger()
+	prompt := input.Prompt
+	for _, r := range c.rewriters {
+		next, err := safecall.Do(func() (string, error) {
+			return r.rewriter.Rewrite(input, prompt)
+		})
+		if err != nil {
+			logger.Warn("prompt rewriter failed", "rewriter", r.name, "error", err)
+			continue
+		}
+		if next != prompt {
+			logger.Debug("prompt rewritten", "rewriter", r.name)
+			prompt = next
+		}
+	}
+	return prompt
+}
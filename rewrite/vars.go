@@ -0,0 +1,91 @@
+package rewrite
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// variable matches "${name}" and the escape "$${".
+var variable = regexp.MustCompile(`\$\$\{|\$\{([^{}\s]+)\}`)
+
+// expand replaces each "${name}" in s with what lookup returns for name,
+// leaving the variables it does not know as written. With unescape set,
+// "$${" becomes "${".
+func expand(s string, lookup func(name string) (string, bool), unescape bool) string {
+	return variable.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			if unescape {
+				return "${"
+			}
+			return match
+		}
+		if value, ok := lookup(match[2 : len(match)-1]); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// Variables substitutes variables in the prompt:
+//
+//	${cwd}          the session's working directory
+//	${session_id}   the session ID
+//	${state:key}    the value stored under key in the session state
+//	${env:NAME}     the environment variable NAME, if listed in env
+//
+// Only the environment variables listed are substituted, so that a prompt
+// cannot send arbitrary secrets to the model. A state value that is not a
+// string is substituted as JSON. Variables that are unknown, unset or not
+// allowed are left as written, and "$${" stands for a literal "${".
+func Variables(env ...string) Rewriter {
+	allowed := make(map[string]bool, len(env))
+	for _, name := range env {
+		allowed[name] = true
+	}
+	return RewriterFunc(func(input types.UserPromptSubmitInput, prompt string) (string, error) {
+		var err error
+		lookup := func(name string) (string, bool) {
+			switch {
+			case name == "cwd":
+				return input.CWD, true
+			case name == "session_id":
+				return input.SessionID, true
+			case strings.HasPrefix(name, "env:"):
+				name = strings.TrimPrefix(name, "env:")
+				if !allowed[name] {
+					return "", false
+				}
+				return os.LookupEnv(name)
+			case strings.HasPrefix(name, "state:"):
+				value, ok, lookupErr := stateValue(input, strings.TrimPrefix(name, "state:"))
+				if lookupErr != nil && err == nil {
+					err = lookupErr
+				}
+				return value, ok
+			}
+			return "", false
+		}
+		rewritten := expand(prompt, lookup, true)
+		if err != nil {
+			return prompt, err
+		}
+		return rewritten, nil
+	})
+}
+
+func stateValue(input types.HookInput, key string) (string, bool, error) {
+	raw, found, err := state.Get[json.RawMessage](input.Context(), key)
+	if err != nil || !found {
+		return "", false, err
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s, true, nil
+	}
+	return string(raw), true, nil
+}
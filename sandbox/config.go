@@ -0,0 +1,176 @@
+// Package sandbox enforces a workspace boundary for the file-touching tools
+// (Read, Write, Edit, MultiEdit, Glob and Grep).
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Config describes the boundary. Relative roots are resolved against the
+// session's working directory; "~" expands to the user's home directory.
+// Without roots the working directory is the only root. ReadRoots are
+// additionally allowed for the read-only tools Read, Glob and Grep.
+//
+// Protected patterns use glob syntax. A pattern without a slash matches a
+// file name at any depth (".env"), a pattern ending in a slash matches a
+// directory and everything below it (".git/", "~/.ssh/"), and any other
+// pattern is matched against the full resolved path.
+type Config struct {
+	Roots             []string                 `json:"roots,omitempty" yaml:"roots,omitempty"`
+	ReadRoots         []string                 `json:"read_roots,omitempty" yaml:"read_roots,omitempty"`
+	Protected         []string                 `json:"protected,omitempty" yaml:"protected,omitempty"`
+	EscapeDecision    types.PermissionDecision `json:"escape_decision,omitempty" yaml:"escape_decision,omitempty"`
+	ProtectedDecision types.PermissionDecision `json:"protected_decision,omitempty" yaml:"protected_decision,omitempty"`
+}
+
+var DefaultProtected = []string{
+	ProjectConfigFile,
+	".claude/hooks*.{yaml,yml,json}",
+	"~/.claude/hooks*.{yaml,yml,json}",
+	".env",
+	".env.*",
+	".git/",
+	"*.pem",
+	"*.key",
+	"~/.ssh/",
+	"~/.aws/",
+	"~/.gnupg/",
+	"~/.config/gcloud/",
+	"~/.kube/",
+	"~/.docker/config.json",
+	"~/.netrc",
+}
+
+// DefaultConfig confines tools to the working directory and protects
+// DefaultProtected, denying anything else.
+func DefaultConfig() Config {
+	return Config{
+		Roots:             []string{"."},
+		Protected:         append([]string(nil), DefaultProtected...),
+		EscapeDecision:    types.PermissionDeny,
+		ProtectedDecision: types.PermissionDeny,
+	}
+}
+
+// LoadConfig reads a configuration file. Files ending in .yaml or .yml are
+// decoded as YAML, everything else as JSON.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read sandbox config: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&cfg)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&cfg)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("%s: failed to parse sandbox config: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c Config) Validate() error {
+	for _, d := range []types.PermissionDecision{c.EscapeDecision, c.ProtectedDecision} {
+		if d != "" && d != types.PermissionDeny && d != types.PermissionAsk {
+			return fmt.Errorf("invalid sandbox decision %q: must be deny or ask", d)
+		}
+	}
+	return nil
+}
+
+// Merge layers a trusted override on top of c: roots and protected patterns
+// are appended, decisions are replaced when set. Use Narrow for
+// configuration the model may be able to write.
+func (c Config) Merge(override Config) Config {
+	merged := Config{
+		Roots:             append(append([]string(nil), c.Roots...), override.Roots...),
+		ReadRoots:         append(append([]string(nil), c.ReadRoots...), override.ReadRoots...),
+		Protected:         append(append([]string(nil), c.Protected...), override.Protected...),
+		EscapeDecision:    c.EscapeDecision,
+		ProtectedDecision: c.ProtectedDecision,
+	}
+	if override.EscapeDecision != "" {
+		merged.EscapeDecision = override.EscapeDecision
+	}
+	if override.ProtectedDecision != "" {
+		merged.ProtectedDecision = override.ProtectedDecision
+	}
+	return merged
+}
+
+// Narrow layers a project configuration on top of c such that it can only
+// tighten the boundary: protected patterns are appended, decisions are
+// replaced only by stricter ones, and roots and read roots are kept only if
+// they lie inside c's roots, resolved against cwd. Project roots replace
+// c's roots when at least one of them is kept, and so do read roots.
+func (c Config) Narrow(project Config, cwd string) Config {
+	narrowed := Config{
+		Roots:             c.Roots,
+		ReadRoots:         c.ReadRoots,
+		Protected:         append(append([]string(nil), c.Protected...), project.Protected...),
+		EscapeDecision:    stricter(c.EscapeDecision, project.EscapeDecision),
+		ProtectedDecision: stricter(c.ProtectedDecision, project.ProtectedDecision),
+	}
+	roots := c.Roots
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	if kept := inside(project.Roots, roots, cwd); len(kept) > 0 {
+		narrowed.Roots = kept
+	}
+	if kept := inside(project.ReadRoots, append(append([]string(nil), roots...), c.ReadRoots...), cwd); len(kept) > 0 {
+		narrowed.ReadRoots = kept
+	}
+	return narrowed
+}
+
+// stricter returns override if it is stricter than base. An empty decision
+// means deny.
+func stricter(base, override types.PermissionDecision) types.PermissionDecision {
+	if decisionOr(base) == types.PermissionAsk && override == types.PermissionDeny {
+		return override
+	}
+	return base
+}
+
+// inside returns the paths lying inside one of roots.
+func inside(paths, roots []string, cwd string) []string {
+	var resolved []string
+	for _, root := range roots {
+		if abs, ok := absolute(cwd, root); ok {
+			resolved = append(resolved, resolveSymlinks(abs))
+		}
+	}
+	var kept []string
+	for _, path := range paths {
+		abs, ok := absolute(cwd, path)
+		if !ok {
+			continue
+		}
+		abs = resolveSymlinks(abs)
+		for _, root := range resolved {
+			if within(root, abs) {
+				kept = append(kept, path)
+				break
+			}
+		}
+	}
+	return kept
+}
@@ -0,0 +1,374 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/glob"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// ProjectConfigFile is the conventional location of per-project sandbox
+// settings, relative to the project root.
+const ProjectConfigFile = ".claude/sandbox.json"
+
+const toolNotebookEdit types.ToolName = "NotebookEdit"
+
+var readOnlyTools = map[types.ToolName]bool{
+	types.ToolRead: true,
+	types.ToolGlob: true,
+	types.ToolGrep: true,
+}
+
+// Sandbox is a PreToolUse handler that denies or asks for file access
+// outside the configured roots or to protected paths. It implements both
+// handler.Handler and handler.PreToolUseHandler.
+type Sandbox struct {
+	config      Config
+	projectFile string
+}
+
+func New(config Config) *Sandbox {
+	return &Sandbox{config: config}
+}
+
+// WithProjectFile makes the sandbox narrow its base configuration with the
+// configuration file at name, relative to each session's working directory;
+// see Config.Narrow. The file itself is protected. A missing file is not an
+// error.
+func (s *Sandbox) WithProjectFile(name string) *Sandbox {
+	s.projectFile = name
+	return s
+}
+
+// Violation describes a path that crossed the boundary.
+type Violation struct {
+	Tool     types.ToolName
+	Argument string
+	Path     string
+	Resolved string
+	Pattern  string
+	Decision types.PermissionDecision
+	Reason   string
+}
+
+func (s *Sandbox) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	if preInput, ok := input.(types.PreToolUseInput); ok && eventName == types.EventPreToolUse {
+		return s.HandlePreToolUse(preInput)
+	}
+	return types.Success(), nil
+}
+
+func (s *Sandbox) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
+	violation, err := s.Check(input)
+	if err != nil {
+		return types.PreToolUseOutput{}, err
+	}
+	if violation == nil {
+		return types.PreToolUseOutput{}, nil
+	}
+	return types.Permission(violation.Decision, violation.Reason), nil
+}
+
+// Check returns the first path argument of input that violates the
+// boundary, or nil if the call stays inside it or does not touch files.
+func (s *Sandbox) Check(input types.PreToolUseInput) (*Violation, error) {
+	args := pathArguments(input)
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	config, err := s.effectiveConfig(input.CWD)
+	if err != nil {
+		return nil, err
+	}
+	b, err := compile(config, input.CWD, readOnlyTools[input.ToolName])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arg := range args {
+		if v := b.check(arg.name, arg.value); v != nil {
+			v.Tool = input.ToolName
+			v.Reason = fmt.Sprintf("%s %s: %s", input.ToolName, arg.name, v.Reason)
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Sandbox) effectiveConfig(cwd string) (Config, error) {
+	config := s.config
+	if s.projectFile == "" || cwd == "" {
+		return config, nil
+	}
+	config.Protected = append(append([]string(nil), config.Protected...), s.projectFile)
+
+	path := s.projectFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(cwd, path)
+	}
+	project, err := LoadConfig(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	return config.Narrow(project, cwd), nil
+}
+
+type pathArgument struct {
+	name  string
+	value string
+}
+
+func pathArguments(input types.PreToolUseInput) []pathArgument {
+	str := func(key string) (string, bool) {
+		v, ok := input.ToolInput[key].(string)
+		return v, ok && v != ""
+	}
+
+	var args []pathArgument
+	switch input.ToolName {
+	case types.ToolRead, types.ToolWrite, types.ToolEdit, types.ToolMultiEdit:
+		if v, ok := str("file_path"); ok {
+			args = append(args, pathArgument{"file_path", v})
+		}
+	case toolNotebookEdit:
+		if v, ok := str("notebook_path"); ok {
+			args = append(args, pathArgument{"notebook_path", v})
+		}
+	case types.ToolGlob, types.ToolGrep:
+		base, ok := str("path")
+		if ok {
+			args = append(args, pathArgument{"path", base})
+		} else {
+			base = "."
+			args = append(args, pathArgument{"path", "."})
+		}
+		if pattern, ok := str("pattern"); ok && input.ToolName == types.ToolGlob {
+			if prefix := staticPrefix(pattern); prefix != "" {
+				if !filepath.IsAbs(prefix) && !strings.HasPrefix(prefix, "~") {
+					prefix = filepath.Join(base, prefix)
+				}
+				args = append(args, pathArgument{"pattern", prefix})
+			}
+		}
+	}
+	return args
+}
+
+// staticPrefix returns the leading path segments of a glob pattern that
+// contain no wildcards.
+func staticPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var static []string
+	for _, segment := range segments {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		static = append(static, segment)
+	}
+	if len(static) == 0 {
+		return ""
+	}
+	prefix := strings.Join(static, "/")
+	if prefix == "" {
+		return "/"
+	}
+	return prefix
+}
+
+type protectedPattern struct {
+	source string
+	match  func(path string) bool
+}
+
+type boundary struct {
+	cwd       string
+	roots     []string
+	protected []protectedPattern
+	config    Config
+}
+
+func compile(config Config, cwd string, readOnly bool) (*boundary, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	b := &boundary{cwd: cwd, config: config}
+
+	roots := config.Roots
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	if readOnly {
+		roots = append(append([]string(nil), roots...), config.ReadRoots...)
+	}
+	for _, root := range roots {
+		abs, ok := absolute(cwd, root)
+		if !ok {
+			continue
+		}
+		b.roots = append(b.roots, resolveSymlinks(abs))
+	}
+
+	for _, source := range config.Protected {
+		p, err := compileProtected(cwd, source)
+		if err != nil {
+			return nil, err
+		}
+		b.protected = append(b.protected, p)
+	}
+	return b, nil
+}
+
+func compileProtected(cwd, source string) (protectedPattern, error) {
+	pattern := expandHome(source)
+	isDir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	if !strings.Contains(pattern, "/") {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			return protectedPattern{}, err
+		}
+		if isDir {
+			// Match any directory segment of the path.
+			return protectedPattern{source: source, match: func(path string) bool {
+				dir := path
+				for {
+					parent := filepath.Dir(dir)
+					if parent == dir {
+						return false
+					}
+					if g.Match(filepath.Base(dir)) {
+						return true
+					}
+					dir = parent
+				}
+			}}, nil
+		}
+		return protectedPattern{source: source, match: func(path string) bool {
+			return g.Match(filepath.Base(path))
+		}}, nil
+	}
+
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(cwd, pattern)
+	}
+	exact, err := glob.Compile(filepath.ToSlash(pattern))
+	if err != nil {
+		return protectedPattern{}, err
+	}
+	below, err := glob.Compile(filepath.ToSlash(pattern) + "/**")
+	if err != nil {
+		return protectedPattern{}, err
+	}
+	return protectedPattern{source: source, match: func(path string) bool {
+		path = filepath.ToSlash(path)
+		return exact.Match(path) || below.Match(path)
+	}}, nil
+}
+
+func (b *boundary) check(name, value string) *Violation {
+	abs, ok := absolute(b.cwd, value)
+	if !ok {
+		return &Violation{
+			Argument: name,
+			Path:     value,
+			Decision: decisionOr(b.config.EscapeDecision),
+			Reason:   fmt.Sprintf("cannot resolve %s relative to the working directory", value),
+		}
+	}
+	resolved := resolveSymlinks(abs)
+
+	for _, p := range b.protected {
+		if p.match(abs) || p.match(resolved) {
+			return &Violation{
+				Argument: name,
+				Path:     value,
+				Resolved: resolved,
+				Pattern:  p.source,
+				Decision: decisionOr(b.config.ProtectedDecision),
+				Reason:   fmt.Sprintf("%s resolves to %s, which matches protected pattern %q", value, resolved, p.source),
+			}
+		}
+	}
+
+	for _, root := range b.roots {
+		if within(root, resolved) {
+			return nil
+		}
+	}
+	return &Violation{
+		Argument: name,
+		Path:     value,
+		Resolved: resolved,
+		Decision: decisionOr(b.config.EscapeDecision),
+		Reason:   fmt.Sprintf("%s resolves to %s, which is outside the allowed roots (%s)", value, resolved, strings.Join(b.roots, ", ")),
+	}
+}
+
+func decisionOr(d types.PermissionDecision) types.PermissionDecision {
+	if d == "" {
+		return types.PermissionDeny
+	}
+	return d
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func absolute(cwd, path string) (string, bool) {
+	path = expandHome(path)
+	if strings.HasPrefix(path, "~") {
+		return "", false
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	if cwd == "" {
+		return "", false
+	}
+	return filepath.Clean(filepath.Join(cwd, path)), true
+}
+
+// resolveSymlinks resolves symlinks in the longest existing prefix of path,
+// so paths to files that are about to be created are still resolved through
+// any linked parent directory.
+func resolveSymlinks(path string) string {
+	existing := path
+	var rest []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
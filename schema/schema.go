@@ -0,0 +1,247 @@
+// Package schema generates JSON Schema (draft 2020-12) documents from the
+// hook input and output types in the types package.
+package schema
+
+//go:generate go run ../cmd/hookctl schema -out ../schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const (
+	Draft   = "https://json-schema.org/draft/2020-12/schema"
+	BaseURI = "https://github.com/HeroSizy/claude-code-hooks-go-sdk/schemas/"
+)
+
+type Kind string
+
+const (
+	KindInput  Kind = "input"
+	KindOutput Kind = "output"
+)
+
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Const       interface{}        `json:"const,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Additional  *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Document is a generated schema together with the event and direction it
+// describes.
+type Document struct {
+	Event  types.EventName
+	Kind   Kind
+	Schema *Schema
+}
+
+// FileName returns the conventional file name for the document, e.g.
+// "PreToolUse.input.json".
+func (d Document) FileName() string {
+	return fmt.Sprintf("%s.%s.json", d.Event, d.Kind)
+}
+
+// Marshal renders the document as indented JSON terminated by a newline.
+func (d Document) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(d.Schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+var events = []types.EventName{
+	types.EventPreToolUse,
+	types.EventPostToolUse,
+	types.EventNotification,
+	types.EventUserPromptSubmit,
+	types.EventStop,
+	types.EventSubagentStop,
+	types.EventPreCompact,
+	types.EventSessionStart,
+}
+
+var inputTypes = map[types.EventName]interface{}{
+	types.EventPreToolUse:       types.PreToolUseInput{},
+	types.EventPostToolUse:      types.PostToolUseInput{},
+	types.EventNotification:     types.NotificationInput{},
+	types.EventUserPromptSubmit: types.UserPromptSubmitInput{},
+	types.EventStop:             types.StopInput{},
+	types.EventSubagentStop:     types.SubagentStopInput{},
+	types.EventPreCompact:       types.PreCompactInput{},
+	types.EventSessionStart:     types.SessionStartInput{},
+}
+
+var outputTypes = map[types.EventName]interface{}{
+	types.EventPreToolUse:       types.PreToolUseOutput{},
+	types.EventPostToolUse:      types.PostToolUseOutput{},
+	types.EventNotification:     types.NotificationOutput{},
+	types.EventUserPromptSubmit: types.UserPromptSubmitOutput{},
+	types.EventStop:             types.StopOutput{},
+	types.EventSubagentStop:     types.SubagentStopOutput{},
+	types.EventPreCompact:       types.PreCompactOutput{},
+	types.EventSessionStart:     types.SessionStartOutput{},
+}
+
+// enumValues lists the allowed values of the string enums in the types
+// package. Types that are deliberately open (ToolName also carries MCP tool
+// names) are not listed.
+var enumValues = map[reflect.Type][]string{
+	reflect.TypeOf(types.CompactTrigger("")): {
+		string(types.CompactTriggerManual),
+		string(types.CompactTriggerAuto),
+	},
+	reflect.TypeOf(types.EventName("")): {
+		string(types.EventPreToolUse),
+		string(types.EventPostToolUse),
+		string(types.EventNotification),
+		string(types.EventUserPromptSubmit),
+		string(types.EventStop),
+		string(types.EventSubagentStop),
+		string(types.EventPreCompact),
+		string(types.EventSessionStart),
+	},
+	reflect.TypeOf(types.PermissionDecision("")): {
+		string(types.PermissionAllow),
+		string(types.PermissionDeny),
+		string(types.PermissionAsk),
+	},
+	reflect.TypeOf(types.SessionSource("")): {
+		string(types.SessionSourceStartup),
+		string(types.SessionSourceResume),
+		string(types.SessionSourceClear),
+	},
+}
+
+func Events() []types.EventName {
+	return append([]types.EventName(nil), events...)
+}
+
+// ForEvent returns the input and output schema documents for eventName.
+func ForEvent(eventName types.EventName) (Document, Document, error) {
+	in, ok := inputTypes[eventName]
+	if !ok {
+		return Document{}, Document{}, &types.InvalidEventError{EventName: string(eventName)}
+	}
+	out := outputTypes[eventName]
+
+	input := Document{Event: eventName, Kind: KindInput, Schema: Generate(in)}
+	input.Schema.Properties["hook_event_name"] = &Schema{Type: "string", Const: string(eventName)}
+	input.annotate()
+
+	output := Document{Event: eventName, Kind: KindOutput, Schema: Generate(out)}
+	output.annotate()
+
+	return input, output, nil
+}
+
+// All returns every input and output document, ordered by event.
+func All() ([]Document, error) {
+	docs := make([]Document, 0, 2*len(events))
+	for _, eventName := range events {
+		in, out, err := ForEvent(eventName)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, in, out)
+	}
+	return docs, nil
+}
+
+func (d *Document) annotate() {
+	d.Schema.Schema = Draft
+	d.Schema.ID = BaseURI + d.FileName()
+	d.Schema.Title = fmt.Sprintf("%s %s", d.Event, d.Kind)
+}
+
+// Generate builds a schema for the Go value v by reflecting over its type and
+// json struct tags.
+func Generate(v interface{}) *Schema {
+	return forType(reflect.TypeOf(v))
+}
+
+func forType(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	if values, ok := enumValues[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return forType(t.Elem())
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: forType(t.Elem())}
+	case reflect.Map:
+		s := &Schema{Type: "object"}
+		if elem := forType(t.Elem()); elem.Type != "" || elem.Enum != nil {
+			s.Additional = elem
+		}
+		return s
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addFields(s, t)
+		sort.Strings(s.Required)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		s.Properties[name] = forType(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
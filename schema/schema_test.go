@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSchemasUpToDate fails when the committed schemas no longer match the
+// Go types. Run 'go generate ./schema' to update them.
+func TestSchemasUpToDate(t *testing.T) {
+	docs, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		name := doc.FileName()
+		want[name] = true
+		generated, err := doc.Marshal()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		committed, err := os.ReadFile(filepath.Join("..", "schemas", name))
+		if err != nil {
+			t.Errorf("%s: %v; run 'go generate ./schema'", name, err)
+			continue
+		}
+		if !bytes.Equal(generated, committed) {
+			t.Errorf("schemas/%s is out of date; run 'go generate ./schema'", name)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join("..", "schemas", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if !want[filepath.Base(file)] {
+			t.Errorf("schemas/%s matches no event; remove it", filepath.Base(file))
+		}
+	}
+}
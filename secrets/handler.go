@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const maxReportedFindings = 3
+
+// Guard is a handler that stops tool calls and prompts carrying secrets. It
+// implements handler.Handler, handler.PreToolUseHandler and
+// handler.UserPromptSubmitHandler.
+type Guard struct {
+	scanner  *Scanner
+	decision types.PermissionDecision
+}
+
+// NewGuard returns a guard that denies tool calls containing secrets. A nil
+// scanner uses NewScanner().
+func NewGuard(scanner *Scanner) *Guard {
+	if scanner == nil {
+		scanner = NewScanner()
+	}
+	return &Guard{scanner: scanner, decision: types.PermissionDeny}
+}
+
+// WithDecision sets the decision for tool calls containing secrets, deny or
+// ask. Prompts containing secrets are always blocked.
+func (g *Guard) WithDecision(decision types.PermissionDecision) *Guard {
+	g.decision = decision
+	return g
+}
+
+func (g *Guard) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		return g.HandlePreToolUse(in)
+	case types.UserPromptSubmitInput:
+		return g.HandleUserPromptSubmit(in)
+	}
+	return types.Success(), nil
+}
+
+func (g *Guard) HandlePreToolUse(input types.PreToolUseInput) (types.PreToolUseOutput, error) {
+	findings := g.scanner.ScanInput(input)
+	if len(findings) == 0 {
+		return types.PreToolUseOutput{}, nil
+	}
+	return types.Permission(g.decision, Summarize(findings)), nil
+}
+
+func (g *Guard) HandleUserPromptSubmit(input types.UserPromptSubmitInput) (types.UserPromptSubmitOutput, error) {
+	findings := g.scanner.ScanInput(input)
+	if len(findings) == 0 {
+		return types.UserPromptSubmitOutput{}, nil
+	}
+	reason := Summarize(findings)
+	allowSubmit := false
+	return types.UserPromptSubmitOutput{
+		BaseOutput:  types.BaseOutput{StopReason: &reason},
+		AllowSubmit: &allowSubmit,
+	}, nil
+}
+
+// Summarize renders findings as a single reason string using only redacted
+// previews.
+func Summarize(findings []Finding) string {
+	parts := make([]string, 0, maxReportedFindings)
+	for i, f := range findings {
+		if i == maxReportedFindings {
+			parts = append(parts, fmt.Sprintf("and %d more", len(findings)-maxReportedFindings))
+			break
+		}
+		parts = append(parts, f.String())
+	}
+	return "Possible secret detected: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,143 @@
+// Package secrets detects credentials in text that is about to be written,
+// executed or sent to the model.
+package secrets
+
+import (
+	"math"
+	"regexp"
+)
+
+// Rule detects one credential format. SecretGroup selects the capture group
+// holding the secret itself (0 for the whole match); MinEntropy, when set,
+// discards candidates whose Shannon entropy in bits per character is lower.
+type Rule struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+	SecretGroup int
+	MinEntropy  float64
+}
+
+var DefaultRules = []Rule{
+	{
+		ID:          "private-key",
+		Description: "Private key block",
+		Pattern:     regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`),
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|access).{0,20}?['"]?\s*[:=]\s*['"]?([A-Za-z0-9/+]{40})\b`),
+		SecretGroup: 1,
+		MinEntropy:  4.0,
+	},
+	{
+		ID:          "gcp-api-key",
+		Description: "Google Cloud API key",
+		Pattern:     regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+	},
+	{
+		ID:          "gcp-service-account",
+		Description: "Google Cloud service account key",
+		Pattern:     regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{40})"`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "azure-storage-key",
+		Description: "Azure storage account key",
+		Pattern:     regexp.MustCompile(`AccountKey=([A-Za-z0-9+/]{86}==)`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab personal access token",
+		Pattern:     regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`),
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Pattern:     regexp.MustCompile(`https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+`),
+	},
+	{
+		ID:          "stripe-key",
+		Description: "Stripe secret key",
+		Pattern:     regexp.MustCompile(`\b[sr]k_(?:live|test)_[0-9A-Za-z]{24,}\b`),
+	},
+	{
+		ID:          "anthropic-api-key",
+		Description: "Anthropic API key",
+		Pattern:     regexp.MustCompile(`\bsk-ant-[a-z]+\d{2}-[A-Za-z0-9_\-]{80,}`),
+	},
+	{
+		ID:          "openai-api-key",
+		Description: "OpenAI API key",
+		Pattern:     regexp.MustCompile(`\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_\-]{20,}T3BlbkFJ[A-Za-z0-9_\-]{20,}`),
+	},
+	{
+		ID:          "npm-token",
+		Description: "npm access token",
+		Pattern:     regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`),
+	},
+	{
+		ID:          "jwt",
+		Description: "JSON Web Token",
+		Pattern:     regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		ID:          "connection-string",
+		Description: "Connection string with embedded password",
+		Pattern:     regexp.MustCompile(`\b(?:postgres(?:ql)?|mysql|mariadb|mongodb(?:\+srv)?|redis|rediss|amqps?|mssql|sqlserver|ftp|smtp)://[^\s:/@'"]+:([^\s@'"/]+)@[^\s'"]+`),
+		SecretGroup: 1,
+	},
+	{
+		ID:          "generic-assignment",
+		Description: "Credential assigned to a secret-looking name",
+		Pattern:     regexp.MustCompile(`(?i)\b[\w.-]*(?:api[_-]?key|secret|token|passw(?:or)?d|pwd|credential|auth[_-]?key)[\w.-]*["']?\s*(?:[:=]|:=|=>)\s*["']?([^\s"'` + "`" + `,;]{12,})`),
+		SecretGroup: 1,
+		MinEntropy:  3.5,
+	},
+}
+
+// DefaultAllow matches candidate secrets that are obviously placeholders.
+var DefaultAllow = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)example|sample|dummy|placeholder|changeme|redacted|your[_-]|<[^>]*>|x{6,}|\*{4,}`),
+	regexp.MustCompile(`^\$\{?[A-Za-z_][A-Za-z0-9_]*\}?$`),
+	regexp.MustCompile(`^\{\{.*\}\}$`),
+	// References to variables or config rather than literal values.
+	regexp.MustCompile(`^(?:process\.env|os\.(?:environ|getenv|Getenv)|System\.getenv|ENV\[)`),
+	regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+(?:\(\))?$`),
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,268 @@
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Finding is a detected secret. Start and End are byte offsets of the secret
+// within the scanned field; Line and Column are 1-based. Preview is the
+// surrounding line with the secret redacted and is safe to log.
+type Finding struct {
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description"`
+	Field       string  `json:"field"`
+	Start       int     `json:"start"`
+	End         int     `json:"end"`
+	Line        int     `json:"line"`
+	Column      int     `json:"column"`
+	Preview     string  `json:"preview"`
+	Entropy     float64 `json:"entropy"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s in %s at line %d, column %d: %s", f.Description, f.Field, f.Line, f.Column, f.Preview)
+}
+
+type Scanner struct {
+	rules            []Rule
+	allow            []*regexp.Regexp
+	entropyMinLength int
+	entropyThreshold float64
+}
+
+type Option func(*Scanner)
+
+// WithRules replaces the rule set.
+func WithRules(rules ...Rule) Option {
+	return func(s *Scanner) {
+		s.rules = append([]Rule(nil), rules...)
+	}
+}
+
+// WithExtraRules adds rules after the current rule set.
+func WithExtraRules(rules ...Rule) Option {
+	return func(s *Scanner) {
+		s.rules = append(s.rules, rules...)
+	}
+}
+
+// WithAllow adds patterns for candidate secrets that should be ignored.
+func WithAllow(patterns ...*regexp.Regexp) Option {
+	return func(s *Scanner) {
+		s.allow = append(s.allow, patterns...)
+	}
+}
+
+// WithHighEntropyStrings additionally reports any token of at least
+// minLength base64 or hex characters whose entropy reaches threshold. This
+// catches unknown credential formats at the cost of false positives on
+// hashes and encoded data; 24 and 4.5 are reasonable starting values.
+func WithHighEntropyStrings(minLength int, threshold float64) Option {
+	return func(s *Scanner) {
+		s.entropyMinLength = minLength
+		s.entropyThreshold = threshold
+	}
+}
+
+// NewScanner returns a scanner using DefaultRules and DefaultAllow unless
+// overridden by options.
+func NewScanner(opts ...Option) *Scanner {
+	s := &Scanner{
+		rules: append([]Rule(nil), DefaultRules...),
+		allow: append([]*regexp.Regexp(nil), DefaultAllow...),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+var entropyToken = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// Scan reports the secrets found in text. Rules are applied in order and a
+// match overlapping an earlier finding is dropped, so more specific rules
+// should come first.
+func (s *Scanner) Scan(field, text string) []Finding {
+	var findings []Finding
+	overlaps := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.End && f.Start < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range s.rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			group := rule.SecretGroup
+			if 2*group+1 >= len(m) || m[2*group] < 0 {
+				group = 0
+			}
+			start, end := m[2*group], m[2*group+1]
+			secret := text[start:end]
+
+			entropy := Entropy(secret)
+			if rule.MinEntropy > 0 && entropy < rule.MinEntropy {
+				continue
+			}
+			if s.allowed(secret) || overlaps(start, end) {
+				continue
+			}
+			findings = append(findings, newFinding(rule.ID, rule.Description, field, text, start, end, entropy))
+		}
+	}
+
+	if s.entropyMinLength > 0 {
+		for _, m := range entropyToken.FindAllStringIndex(text, -1) {
+			start, end := m[0], m[1]
+			if end-start < s.entropyMinLength {
+				continue
+			}
+			secret := text[start:end]
+			entropy := Entropy(secret)
+			threshold := s.entropyThreshold
+			if isHex(secret) {
+				// Hex strings carry at most 4 bits per character.
+				threshold = threshold * 3 / 4
+			}
+			if entropy < threshold || s.allowed(secret) || overlaps(start, end) {
+				continue
+			}
+			findings = append(findings, newFinding("high-entropy-string", "High-entropy string", field, text, start, end, entropy))
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+func (s *Scanner) allowed(secret string) bool {
+	for _, re := range s.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') && !(c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func newFinding(ruleID, description, field, text string, start, end int, entropy float64) Finding {
+	line := 1 + strings.Count(text[:start], "\n")
+	lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+	lineEnd := strings.IndexByte(text[end:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(text)
+	} else {
+		lineEnd += end
+	}
+
+	return Finding{
+		RuleID:      ruleID,
+		Description: description,
+		Field:       field,
+		Start:       start,
+		End:         end,
+		Line:        line,
+		Column:      start - lineStart + 1,
+		Preview:     preview(text[lineStart:start], Redact(text[start:end]), text[end:lineEnd]),
+		Entropy:     entropy,
+	}
+}
+
+const previewContext = 40
+
+func preview(before, redacted, after string) string {
+	before = strings.TrimLeft(before, " \t")
+	if len(before) > previewContext {
+		before = "..." + before[len(before)-previewContext:]
+	}
+	if len(after) > previewContext {
+		after = after[:previewContext] + "..."
+	}
+	return before + redacted + after
+}
+
+// Redact masks a secret, keeping at most the first four characters so the
+// kind of credential remains recognizable.
+func Redact(secret string) string {
+	if strings.HasPrefix(secret, "-----BEGIN") {
+		return secret
+	}
+	keep := 4
+	if len(secret) <= 8 {
+		keep = 0
+	}
+	masked := len(secret) - keep
+	if masked > 16 {
+		masked = 16
+	}
+	return secret[:keep] + strings.Repeat("*", masked)
+}
+
+// ScanInput scans the parts of a hook input that can carry secrets: the
+// content written by Write, Edit, MultiEdit and NotebookEdit, Bash commands,
+// and submitted prompts.
+func (s *Scanner) ScanInput(input types.HookInput) []Finding {
+	var findings []Finding
+	for _, field := range Fields(input) {
+		findings = append(findings, s.Scan(field.Name, field.Value)...)
+	}
+	return findings
+}
+
+// Field is a named piece of text extracted from a hook input.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Fields extracts the scannable text from a hook input. Field names use the
+// tool input keys, e.g. "content" or "edits[2].new_string".
+func Fields(input types.HookInput) []Field {
+	var fields []Field
+	add := func(name string, v interface{}) {
+		if s, ok := v.(string); ok && s != "" {
+			fields = append(fields, Field{Name: name, Value: s})
+		}
+	}
+
+	switch in := input.(type) {
+	case types.PreToolUseInput:
+		switch in.ToolName {
+		case types.ToolWrite:
+			add("content", in.ToolInput["content"])
+		case types.ToolEdit:
+			add("new_string", in.ToolInput["new_string"])
+		case types.ToolMultiEdit:
+			if edits, ok := in.ToolInput["edits"].([]interface{}); ok {
+				for i, edit := range edits {
+					if m, ok := edit.(map[string]interface{}); ok {
+						add(fmt.Sprintf("edits[%d].new_string", i), m["new_string"])
+					}
+				}
+			}
+		case types.ToolBash:
+			add("command", in.ToolInput["command"])
+		case "NotebookEdit":
+			add("new_source", in.ToolInput["new_source"])
+		}
+	case types.UserPromptSubmitInput:
+		add("prompt", in.Prompt)
+	}
+	return fields
+}
@@ -0,0 +1,90 @@
+package settings
+
+import (
+	"math"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+)
+
+// TimeoutMargin is added to a router's timeout when installing it, so the
+// host does not kill the hook before the router can answer.
+const TimeoutMargin = 5 * time.Second
+
+// Install registers command for every event in the manifest, replacing
+// earlier registrations of the same command. Hooks of other commands are
+// left alone.
+func (f *File) Install(command string, manifest handler.Manifest) error {
+	if err := f.Uninstall(command); err != nil {
+		return err
+	}
+	hooks, err := f.Hooks()
+	if err != nil {
+		return err
+	}
+	for _, ev := range manifest.Events {
+		entry := Command{Type: "command", Command: command, Timeout: timeoutSeconds(ev.Timeout)}
+		groups := hooks[ev.Event]
+		added := false
+		for i := range groups {
+			if groups[i].Matcher == ev.Matcher {
+				groups[i].Hooks = append(groups[i].Hooks, entry)
+				added = true
+				break
+			}
+		}
+		if !added {
+			groups = append(groups, Matcher{Matcher: ev.Matcher, Hooks: []Command{entry}})
+		}
+		if err := f.SetHooks(ev.Event, groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uninstall removes every registration of command, dropping matcher groups
+// and events left without hooks.
+func (f *File) Uninstall(command string) error {
+	hooks, err := f.Hooks()
+	if err != nil {
+		return err
+	}
+	for _, event := range f.Events() {
+		groups, changed := removeCommand(hooks[event], command)
+		if !changed {
+			continue
+		}
+		if err := f.SetHooks(event, groups); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeCommand(groups []Matcher, command string) ([]Matcher, bool) {
+	changed := false
+	var kept []Matcher
+	for _, group := range groups {
+		var hooks []Command
+		for _, hook := range group.Hooks {
+			if hook.Command == command {
+				changed = true
+				continue
+			}
+			hooks = append(hooks, hook)
+		}
+		if len(hooks) > 0 {
+			group.Hooks = hooks
+			kept = append(kept, group)
+		}
+	}
+	return kept, changed
+}
+
+func timeoutSeconds(timeout time.Duration) int {
+	if timeout <= 0 {
+		return 0
+	}
+	return int(math.Ceil((timeout + TimeoutMargin).Seconds()))
+}
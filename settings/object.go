@@ -0,0 +1,121 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// object is a JSON object that remembers the order of its keys.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func (o *object) set(key string, value json.RawMessage) {
+	if o.values == nil {
+		o.values = make(map[string]json.RawMessage)
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *object) delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *object) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+	*o = object{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		o.set(tok.(string), value)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// render writes the object with each key on its own line, indented by
+// prefix plus unit. Values are written as stored, so values read from the
+// file keep their formatting; values set later must already be indented
+// for their position.
+func (o *object) render(buf *bytes.Buffer, prefix, unit string) error {
+	if len(o.keys) == 0 {
+		buf.WriteString("{}")
+		return nil
+	}
+	buf.WriteString("{\n")
+	for i, key := range o.keys {
+		name, err := encode(key, "", "")
+		if err != nil {
+			return err
+		}
+		buf.WriteString(prefix + unit)
+		buf.Write(name)
+		buf.WriteString(": ")
+		buf.Write(o.values[key])
+		if i < len(o.keys)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(prefix + "}")
+	return nil
+}
+
+// encode marshals v without escaping HTML characters, which are common in
+// hook commands ("&&", "<", ">"), indenting it for a value whose line
+// starts with prefix.
+func encode(v interface{}, prefix, unit string) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if unit != "" {
+		enc.SetIndent(prefix, unit)
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// detectIndent returns the indentation unit of a JSON document: the
+// whitespace starting its first indented line, or two spaces.
+func detectIndent(data []byte) string {
+	for _, line := range bytes.Split(data, []byte("\n"))[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) == 0 {
+			continue
+		}
+		if indent := len(line) - len(trimmed); indent > 0 {
+			return string(line[:indent])
+		}
+		break
+	}
+	return "  "
+}
@@ -0,0 +1,210 @@
+// Package settings reads and edits the hooks section of the host's
+// settings.json files.
+//
+// Edits keep the order of existing keys, the file's indentation and the
+// text of everything outside the hooks being changed, so a diff of the file
+// shows only the intended change.
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Scope selects one of the settings files the host merges.
+type Scope string
+
+const (
+	ScopeUser    Scope = "user"
+	ScopeProject Scope = "project"
+	ScopeLocal   Scope = "local"
+)
+
+// Scopes lists the scopes from lowest to highest precedence.
+var Scopes = []Scope{ScopeUser, ScopeProject, ScopeLocal}
+
+// Path returns the settings file for scope: ~/.claude/settings.json,
+// <project>/.claude/settings.json or <project>/.claude/settings.local.json.
+func Path(scope Scope, projectDir string) (string, error) {
+	switch scope {
+	case ScopeUser:
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".claude", "settings.json"), nil
+	case ScopeProject:
+		return filepath.Join(projectDir, ".claude", "settings.json"), nil
+	case ScopeLocal:
+		return filepath.Join(projectDir, ".claude", "settings.local.json"), nil
+	}
+	return "", fmt.Errorf("unknown settings scope %q (want user, project or local)", scope)
+}
+
+// Command is one hook the host runs. Timeout is in seconds; zero uses the
+// host's default of 60.
+type Command struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Timeout int    `json:"timeout,omitempty"`
+}
+
+// Matcher groups the commands run for events matching Matcher. For tool
+// events it is a regular expression over the tool name; empty and "*"
+// match everything.
+type Matcher struct {
+	Matcher string    `json:"matcher,omitempty"`
+	Hooks   []Command `json:"hooks"`
+}
+
+// Hooks maps event names to their matcher groups.
+type Hooks map[types.EventName][]Matcher
+
+// File is a parsed settings file.
+type File struct {
+	Path   string
+	root   *object
+	indent string
+}
+
+// Load reads a settings file. A missing file yields an empty File that
+// Save creates.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{Path: path, root: &object{}, indent: "  "}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, data)
+}
+
+// Parse parses the contents of the settings file at path.
+func Parse(path string, data []byte) (*File, error) {
+	root := &object{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, root); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return &File{Path: path, root: root, indent: detectIndent(data)}, nil
+}
+
+// Hooks returns the hooks configured in the file.
+func (f *File) Hooks() (Hooks, error) {
+	hooks := Hooks{}
+	section, err := f.hooksSection()
+	if err != nil || section == nil {
+		return hooks, err
+	}
+	for _, key := range section.keys {
+		var groups []Matcher
+		if err := json.Unmarshal(section.values[key], &groups); err != nil {
+			return nil, fmt.Errorf("%s: hooks.%s: %w", f.Path, key, err)
+		}
+		hooks[types.EventName(key)] = groups
+	}
+	return hooks, nil
+}
+
+// Events returns the events in the hooks section in file order.
+func (f *File) Events() []types.EventName {
+	section, err := f.hooksSection()
+	if err != nil || section == nil {
+		return nil
+	}
+	events := make([]types.EventName, len(section.keys))
+	for i, key := range section.keys {
+		events[i] = types.EventName(key)
+	}
+	return events
+}
+
+// SetHooks replaces the matcher groups of an event, removing the event when
+// groups is empty and the hooks section when no event is left.
+func (f *File) SetHooks(event types.EventName, groups []Matcher) error {
+	section, err := f.hooksSection()
+	if err != nil {
+		return err
+	}
+	if section == nil {
+		section = &object{}
+	}
+	if len(groups) == 0 {
+		section.delete(string(event))
+	} else {
+		data, err := encode(groups, f.indent+f.indent, f.indent)
+		if err != nil {
+			return err
+		}
+		section.set(string(event), data)
+	}
+
+	if len(section.keys) == 0 {
+		f.root.delete("hooks")
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := section.render(&buf, f.indent, f.indent); err != nil {
+		return err
+	}
+	f.root.set("hooks", buf.Bytes())
+	return nil
+}
+
+func (f *File) hooksSection() (*object, error) {
+	raw, ok := f.root.values["hooks"]
+	if !ok {
+		return nil, nil
+	}
+	section := &object{}
+	if err := json.Unmarshal(raw, section); err != nil {
+		return nil, fmt.Errorf("%s: hooks: %w", f.Path, err)
+	}
+	return section, nil
+}
+
+// Marshal renders the file with its own indentation, or two spaces for a
+// new file. Values the edits did not touch are written as they were read.
+func (f *File) Marshal() ([]byte, error) {
+	var out bytes.Buffer
+	if err := f.root.render(&out, "", f.indent); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// Save writes the file, creating its directory if needed.
+func (f *File) Save() error {
+	data, err := f.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), filepath.Base(f.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(f.Path); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
@@ -0,0 +1,242 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Invokes returns the commands that run one of names, either as the resolved
+// executable or as a wrapper around it (so "sudo rm" invokes both "sudo" and
+// "rm"). A command whose name depends on expansion, such as "$(echo rm)",
+// may run anything and is returned for any names.
+func (s *Script) Invokes(names ...string) []*Command {
+	var out []*Command
+	for _, cmd := range s.Commands() {
+		resolved := cmd.Resolve()
+		if resolved.Dynamic || contains(names, resolved.Name) {
+			out = append(out, cmd)
+			continue
+		}
+		for _, w := range resolved.Wrappers {
+			if contains(names, w) {
+				out = append(out, cmd)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// RecursiveRemovals returns every "rm" invocation with -r, -R or
+// --recursive.
+func (s *Script) RecursiveRemovals() []*Command {
+	var out []*Command
+	for _, cmd := range s.Invokes("rm") {
+		if cmd.Resolve().HasFlag("-r", "-R", "--recursive") {
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
+
+// RecursiveRemoveOutside returns the recursive "rm" invocations that target a
+// path that is not strictly inside cwd: absolute paths elsewhere, ".."
+// escapes, home directory references, the working directory itself, and any
+// operand whose value depends on expansion or, under xargs or "find -exec",
+// on the paths found. Commands whose name depends on expansion count as rm.
+// It also returns the "find -delete" invocations
+// searching outside cwd. Relative paths are resolved against cwd, following
+// earlier "cd" and "pushd" commands in the script.
+func (s *Script) RecursiveRemoveOutside(cwd string) []*Command {
+	var out []*Command
+	dir := cwd
+	for _, cmd := range s.Commands() {
+		resolved := cmd.Resolve()
+		name := resolved.Name
+		if resolved.Dynamic {
+			// The command may turn out to be rm.
+			name = "rm"
+		}
+		switch name {
+		case "cd", "pushd":
+			dir = changeDir(dir, resolved.Operands())
+		case "rm":
+			if !resolved.HasFlag("-r", "-R", "--recursive") {
+				continue
+			}
+			if contains(resolved.Wrappers, "xargs") {
+				out = append(out, cmd)
+				continue
+			}
+			for _, operand := range resolved.Operands() {
+				if operand.Dynamic() || Escapes(cwd, dir, operand.Value) {
+					out = append(out, cmd)
+					break
+				}
+			}
+		case "find":
+			if findDeletesOutside(resolved, cwd, dir) {
+				out = append(out, cmd)
+			}
+		}
+	}
+	return out
+}
+
+// findDeletesOutside reports whether a "find -delete" searches a path that
+// is not inside cwd. Unlike rm, it never removes the starting point itself.
+func findDeletesOutside(resolved Resolved, cwd, dir string) bool {
+	deletes := false
+	for _, arg := range resolved.Args {
+		if arg.Value == "-delete" {
+			deletes = true
+		}
+	}
+	if !deletes {
+		return false
+	}
+	paths := findPaths(resolved.Args)
+	if len(paths) == 0 {
+		paths = []*Word{{Value: "."}}
+	}
+	for _, path := range paths {
+		if path.Dynamic() || (Escapes(cwd, dir, path.Value) && !sameDir(cwd, dir, path.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// findPaths returns the starting points of find, the arguments before its
+// expression. Options such as -L come first.
+func findPaths(args []*Word) []*Word {
+	i := 0
+	for i < len(args) && contains([]string{"-H", "-L", "-P"}, args[i].Value) {
+		i++
+	}
+	start := i
+	for i < len(args) {
+		a := args[i].Value
+		if strings.HasPrefix(a, "-") || a == "(" || a == "!" {
+			break
+		}
+		i++
+	}
+	return args[start:i]
+}
+
+func sameDir(root, dir, path string) bool {
+	abs, ok := absolute(dir, path)
+	return ok && abs == filepath.Clean(root)
+}
+
+func changeDir(dir string, operands []*Word) string {
+	if len(operands) == 0 {
+		home, _ := os.UserHomeDir()
+		return home
+	}
+	target := operands[0]
+	if target.Dynamic() || target.Value == "-" {
+		return ""
+	}
+	path, ok := absolute(dir, target.Value)
+	if !ok {
+		return ""
+	}
+	return path
+}
+
+// Escapes reports whether path, interpreted relative to dir, is not strictly
+// inside root. An empty dir means the current directory is unknown, in which
+// case every relative path escapes.
+func Escapes(root, dir, path string) bool {
+	abs, ok := absolute(dir, path)
+	if !ok {
+		return true
+	}
+	rel, err := filepath.Rel(filepath.Clean(root), abs)
+	if err != nil {
+		return true
+	}
+	return rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func absolute(dir, path string) (string, bool) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		path = home + path[1:]
+	} else if strings.HasPrefix(path, "~") {
+		return "", false
+	}
+
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), true
+	}
+	if dir == "" {
+		return "", false
+	}
+	return filepath.Clean(filepath.Join(dir, path)), true
+}
+
+// PipesInto reports whether any pipeline feeds the output of one of sources
+// into one of sinks, such as "curl ... | sh".
+func (s *Script) PipesInto(sources, sinks []string) bool {
+	found := false
+	s.walkPipelines(func(p *Pipeline) {
+		seenSource := false
+		for _, cmd := range p.Commands {
+			name := cmd.Name()
+			if seenSource && contains(sinks, name) {
+				found = true
+				return
+			}
+			if contains(sources, name) {
+				seenSource = true
+			}
+		}
+	})
+	return found
+}
+
+func (s *Script) walkPipelines(visit func(*Pipeline)) {
+	if s == nil {
+		return
+	}
+	for _, pipeline := range s.Pipelines {
+		visit(pipeline)
+		for _, cmd := range pipeline.Commands {
+			for _, body := range cmd.Body {
+				body.walkPipelines(visit)
+			}
+			for _, w := range cmd.Args {
+				for _, sub := range w.Substitutions {
+					sub.walkPipelines(visit)
+				}
+			}
+			for _, nested := range cmd.inlineScripts() {
+				nested.walkPipelines(visit)
+			}
+		}
+	}
+}
+
+// OutputRedirects returns every redirection that writes to a file, excluding
+// duplications onto other descriptors such as "2>&1" and writes to
+// /dev/null.
+func (s *Script) OutputRedirects() []*Redirect {
+	var out []*Redirect
+	s.walkPipelines(func(p *Pipeline) {
+		for _, cmd := range p.Commands {
+			for _, r := range cmd.Redirects {
+				if r.IsOutputRedirect() && r.Target.Value != "/dev/null" {
+					out = append(out, r)
+				}
+			}
+		}
+	})
+	return out
+}
@@ -0,0 +1,120 @@
+package shell
+
+import "testing"
+
+func TestInvokesInsideExpansions(t *testing.T) {
+	tests := []struct {
+		src  string
+		name string
+		want int
+	}{
+		{`echo ${x:-$(rm -rf /)}`, "rm", 1},
+		{`echo "${x:-$(sudo ls)}"`, "sudo", 1},
+		{`echo ${x:-${y:-$(rm a)}}`, "rm", 1},
+		{"echo ${x:-`rm a`}", "rm", 1},
+		{`echo "${x:-'$(rm a)'}"`, "rm", 1},
+		{`echo ${x:-'$(rm a)'}`, "rm", 0},
+		{`echo $(( $(rm a) + 1 ))`, "rm", 1},
+		{`echo $(( (1 + $(rm a)) * 2 ))`, "rm", 1},
+		{`(( x = $(rm a) ))`, "rm", 1},
+		{`echo ${#x} ${x/a/b} ${x%\}}`, "rm", 0},
+		{`$(echo rm) -rf /`, "rm", 1},
+		{`sudo "$CMD" -rf /`, "rm", 1},
+	}
+	for _, tt := range tests {
+		script, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		if got := len(script.Invokes(tt.name)); got != tt.want {
+			t.Errorf("Parse(%q).Invokes(%q) = %d commands, want %d", tt.src, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNestedExpansionWords(t *testing.T) {
+	tests := []struct {
+		src  string
+		want []string
+	}{
+		{`echo ${a:-${b}} next`, []string{"echo", "${a:-${b}}", "next"}},
+		{`echo "${a:-"}"}" next`, []string{"echo", `${a:-"}"}`, "next"}},
+		{`echo $(( (1 + 2) * 3 )) next`, []string{"echo", "$(( (1 + 2) * 3 ))", "next"}},
+	}
+	for _, tt := range tests {
+		script, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		cmds := script.Commands()
+		if len(cmds) != 1 {
+			t.Errorf("Parse(%q) has %d commands, want 1", tt.src, len(cmds))
+			continue
+		}
+		got := cmds[0].ArgValues()
+		if len(got) != len(tt.want) {
+			t.Errorf("Parse(%q) args = %q, want %q", tt.src, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Parse(%q) args = %q, want %q", tt.src, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestUnterminatedExpansions(t *testing.T) {
+	for _, src := range []string{`echo ${x:-${y}`, `echo $(( 1 + 2 )`, `(( 1 + 2`} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want a syntax error", src)
+		}
+	}
+}
+
+func TestRecursiveRemoveOutside(t *testing.T) {
+	const cwd = "/work/project"
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{`rm -rf build`, false},
+		{`rm -rf /`, true},
+		{`rm -rf ../other`, true},
+		{`rm -rf .`, true},
+		{`rm -rf "$DIR"`, true},
+		{`echo ${x:-$(rm -rf /)}`, true},
+		{`echo "${x:-$(rm -rf /)}"`, true},
+		{`echo $(( $(rm -rf /) ))`, true},
+		{`ls | xargs rm -rf`, true},
+		{`find / -exec rm -rf {} +`, true},
+		{`find . -name '*.o' -execdir rm -rf {} \;`, true},
+		{`find . -name '*.o' -exec rm -f {} +`, false},
+		{`find / -name '*.o' -delete`, true},
+		{`find -L .. -delete`, true},
+		{`find "$DIR" -delete`, true},
+		{`find . -name '*.o' -delete`, false},
+		{`find build -type f -delete`, false},
+		{`find / -name '*.o' -print`, false},
+		{`$(echo rm) -rf /`, true},
+		{`$RM -rf build`, false},
+		{`rm -rf {a,/}`, true},
+		{`rm -rf build/{a,b}`, true},
+		{`rm -rf x{1..3}`, true},
+		{`rm -rf '{a,/}'`, false},
+		{`find . -exec rm -rf {} +`, true},
+	}
+	for _, tt := range tests {
+		script, err := Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.src, err)
+			continue
+		}
+		if got := len(script.RecursiveRemoveOutside(cwd)) > 0; got != tt.want {
+			t.Errorf("RecursiveRemoveOutside(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
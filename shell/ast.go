@@ -0,0 +1,93 @@
+// Package shell parses Bash command lines into a small syntax tree so hooks
+// can reason about what a command actually runs instead of matching
+// substrings.
+//
+// The parser understands quoting, escapes, pipelines, "&&"/"||"/";" lists,
+// subshells and groups, redirections, here-documents, command and process
+// substitution and the common compound commands. It does not expand
+// parameters: words that depend on the environment are marked Dynamic.
+package shell
+
+import "strings"
+
+// Script is a list of pipelines as written in the source.
+type Script struct {
+	Pipelines []*Pipeline
+}
+
+// Pipeline is one or more commands joined by "|" or "|&". Op is the list
+// operator that follows the pipeline ("&&", "||", ";", "&" or "" for the
+// last one).
+type Pipeline struct {
+	Negated  bool
+	Commands []*Command
+	Op       string
+}
+
+// Command is a simple command or a compound command. Compound commands
+// (subshells, groups, case arms, function bodies) keep their nested scripts
+// in Body; for them Args holds the keyword and header words, if any.
+type Command struct {
+	Assignments []*Word
+	Args        []*Word
+	Redirects   []*Redirect
+	Body        []*Script
+	Subshell    bool
+	Function    bool
+}
+
+// Redirect is an I/O redirection such as "2>&1" or ">> log.txt". FD is -1
+// when the default descriptor applies.
+type Redirect struct {
+	FD      int
+	Op      string
+	Target  *Word
+	Heredoc string
+}
+
+// Word is a single shell word after quote removal. Expansions that cannot be
+// resolved statically are kept verbatim in Value.
+type Word struct {
+	Raw           string
+	Value         string
+	Quoted        bool
+	Expansion     bool
+	Glob          bool
+	Brace         bool
+	Substitutions []*Script
+}
+
+// Dynamic reports whether the word's value depends on parameter, command or
+// arithmetic expansion, or expands to several words through braces such as
+// "{a,b}" or "{1..3}".
+func (w *Word) Dynamic() bool {
+	return w.Expansion || w.Brace
+}
+
+func (w *Word) String() string {
+	return w.Value
+}
+
+// IsOutputRedirect reports whether the redirection writes to its target.
+func (r *Redirect) IsOutputRedirect() bool {
+	switch r.Op {
+	case ">", ">>", ">|", "&>", "&>>", "<>":
+		return true
+	default:
+		return false
+	}
+}
+
+// ArgValues returns the values of the command's words, including the command
+// name.
+func (c *Command) ArgValues() []string {
+	values := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+func (c *Command) String() string {
+	return strings.Join(c.ArgValues(), " ")
+}
@@ -0,0 +1,327 @@
+package shell
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Resolved describes the program a simple command ends up executing once
+// wrappers such as sudo, env, nice or xargs are looked through.
+type Resolved struct {
+	Name     string
+	Path     string
+	Args     []*Word
+	Wrappers []string
+	Dynamic  bool
+}
+
+type wrapper struct {
+	shortArgs   string
+	longArgs    []string
+	positional  int
+	assignments bool
+	fallback    string
+}
+
+var wrappers = map[string]wrapper{
+	"sudo":    {shortArgs: "ugCDhprtTU", longArgs: []string{"user", "group", "close-from", "chdir", "host", "prompt", "role", "type", "command-timeout", "other-user"}},
+	"doas":    {shortArgs: "uC"},
+	"env":     {shortArgs: "uCS", longArgs: []string{"unset", "chdir", "split-string"}, assignments: true},
+	"nice":    {shortArgs: "n", longArgs: []string{"adjustment"}},
+	"ionice":  {shortArgs: "cnp", longArgs: []string{"class", "classdata", "pid"}},
+	"nohup":   {},
+	"setsid":  {},
+	"time":    {shortArgs: "fo", longArgs: []string{"format", "output"}},
+	"command": {},
+	"builtin": {},
+	"exec":    {shortArgs: "a"},
+	"timeout": {shortArgs: "sk", longArgs: []string{"signal", "kill-after"}, positional: 1},
+	"stdbuf":  {shortArgs: "ioe", longArgs: []string{"input", "output", "error"}},
+	"chroot":  {longArgs: []string{"userspec", "groups"}, positional: 1},
+	"xargs":   {shortArgs: "IneLlsEadP", longArgs: []string{"replace", "max-args", "max-lines", "max-chars", "eof", "arg-file", "delimiter", "max-procs", "process-slot-var"}, fallback: "echo"},
+	"busybox": {},
+}
+
+// Resolve looks through wrapper commands and returns the executable that
+// actually runs. The name is the base name of the executable as written, so
+// "/bin/rm" and "\rm" both resolve to "rm".
+func (c *Command) Resolve() Resolved {
+	args := c.Args
+	var wrapped []string
+
+	for len(args) > 0 {
+		name := filepath.Base(args[0].Value)
+		w, ok := wrappers[name]
+		if !ok || args[0].Dynamic() {
+			break
+		}
+		if name == "command" && hasAnyFlag(args[1:], "-v", "-V") {
+			break
+		}
+
+		rest := skipWrapperArgs(w, args[1:])
+		if len(rest) == 0 {
+			if w.fallback == "" {
+				break
+			}
+			wrapped = append(wrapped, name)
+			return Resolved{Name: w.fallback, Path: w.fallback, Wrappers: wrapped}
+		}
+		wrapped = append(wrapped, name)
+		args = rest
+	}
+
+	if len(args) == 0 {
+		return Resolved{Wrappers: wrapped}
+	}
+	return Resolved{
+		Name:     filepath.Base(args[0].Value),
+		Path:     args[0].Value,
+		Args:     args[1:],
+		Wrappers: wrapped,
+		// An arithmetic command "(( ... ))" runs no program.
+		Dynamic: args[0].Dynamic() && !strings.HasPrefix(args[0].Raw, "(("),
+	}
+}
+
+// Name returns the resolved executable name, or "" for commands without one.
+func (c *Command) Name() string {
+	return c.Resolve().Name
+}
+
+func skipWrapperArgs(w wrapper, args []*Word) []*Word {
+	i := 0
+	for i < len(args) {
+		a := args[i].Value
+		if a == "--" {
+			i++
+			break
+		}
+		if w.assignments && isAssignment(args[i]) {
+			i++
+			continue
+		}
+		if strings.HasPrefix(a, "--") {
+			name, _, hasValue := strings.Cut(a[2:], "=")
+			i++
+			if !hasValue && contains(w.longArgs, name) {
+				i++
+			}
+			continue
+		}
+		if len(a) > 1 && a[0] == '-' {
+			i++
+			for k := 1; k < len(a); k++ {
+				if strings.IndexByte(w.shortArgs, a[k]) >= 0 {
+					if k == len(a)-1 {
+						i++
+					}
+					break
+				}
+			}
+			continue
+		}
+		break
+	}
+
+	for n := 0; n < w.positional && i < len(args); n++ {
+		i++
+	}
+	if i > len(args) {
+		return nil
+	}
+	return args[i:]
+}
+
+// Flags returns the options passed to the resolved executable. Clustered
+// short options are split, so "-rf" yields "-r" and "-f"; long options are
+// returned without their value. Parsing stops at "--".
+func (r Resolved) Flags() map[string]bool {
+	flags := make(map[string]bool)
+	for _, arg := range r.Args {
+		a := arg.Value
+		if a == "--" {
+			break
+		}
+		if strings.HasPrefix(a, "--") {
+			name, _, _ := strings.Cut(a, "=")
+			flags[name] = true
+			continue
+		}
+		if len(a) > 1 && a[0] == '-' {
+			for _, c := range a[1:] {
+				flags["-"+string(c)] = true
+			}
+		}
+	}
+	return flags
+}
+
+// HasFlag reports whether any of the given flags, such as "-r" or
+// "--recursive", was passed to the resolved executable.
+func (r Resolved) HasFlag(flags ...string) bool {
+	set := r.Flags()
+	for _, flag := range flags {
+		if set[flag] {
+			return true
+		}
+	}
+	return false
+}
+
+// Operands returns the non-option arguments. Option values such as the "5"
+// in "-n 5" cannot be told apart from operands without knowing the program,
+// so they are included.
+func (r Resolved) Operands() []*Word {
+	var operands []*Word
+	endOfOptions := false
+	for _, arg := range r.Args {
+		a := arg.Value
+		if !endOfOptions && a == "--" {
+			endOfOptions = true
+			continue
+		}
+		if !endOfOptions && len(a) > 1 && a[0] == '-' {
+			continue
+		}
+		operands = append(operands, arg)
+	}
+	return operands
+}
+
+func hasAnyFlag(args []*Word, flags ...string) bool {
+	return Resolved{Args: args}.HasFlag(flags...)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var inlineShells = map[string]bool{
+	"sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true,
+}
+
+// Commands returns every simple command reachable from the script in source
+// order: commands in pipelines and lists, inside subshells, groups and
+// compound commands, inside command and process substitutions, and the
+// commands run by "sh -c", "eval" and "find -exec". Strings that fail to
+// parse as shell are skipped.
+func (s *Script) Commands() []*Command {
+	var out []*Command
+	s.walk(func(c *Command) { out = append(out, c) })
+	return out
+}
+
+func (s *Script) walk(visit func(*Command)) {
+	if s == nil {
+		return
+	}
+	for _, pipeline := range s.Pipelines {
+		for _, cmd := range pipeline.Commands {
+			cmd.walk(visit)
+		}
+	}
+}
+
+func (c *Command) walk(visit func(*Command)) {
+	for _, w := range c.Assignments {
+		walkWord(w, visit)
+	}
+	for _, w := range c.Args {
+		walkWord(w, visit)
+	}
+	for _, r := range c.Redirects {
+		walkWord(r.Target, visit)
+	}
+
+	if len(c.Args) > 0 && len(c.Body) == 0 {
+		visit(c)
+		for _, nested := range c.inlineScripts() {
+			nested.walk(visit)
+		}
+	}
+
+	for _, body := range c.Body {
+		body.walk(visit)
+	}
+}
+
+func walkWord(w *Word, visit func(*Command)) {
+	for _, sub := range w.Substitutions {
+		sub.walk(visit)
+	}
+}
+
+func (c *Command) inlineScripts() []*Script {
+	resolved := c.Resolve()
+	var sources []string
+
+	switch {
+	case inlineShells[resolved.Name]:
+		for i, arg := range resolved.Args {
+			if arg.Value == "--" {
+				break
+			}
+			a := arg.Value
+			if len(a) > 1 && a[0] == '-' && !strings.HasPrefix(a, "--") && strings.ContainsRune(a, 'c') {
+				if i+1 < len(resolved.Args) {
+					sources = append(sources, resolved.Args[i+1].Value)
+				}
+				break
+			}
+		}
+	case resolved.Name == "eval":
+		parts := make([]string, len(resolved.Args))
+		for i, arg := range resolved.Args {
+			parts[i] = arg.Value
+		}
+		sources = append(sources, strings.Join(parts, " "))
+	case resolved.Name == "find":
+		var scripts []*Script
+		for i := 0; i < len(resolved.Args); i++ {
+			switch resolved.Args[i].Value {
+			case "-exec", "-execdir", "-ok", "-okdir":
+				start := i + 1
+				end := start
+				for end < len(resolved.Args) && resolved.Args[end].Value != ";" && resolved.Args[end].Value != "+" {
+					end++
+				}
+				if end > start {
+					cmd := &Command{Args: findArgs(resolved.Args[start:end])}
+					scripts = append(scripts, &Script{Pipelines: []*Pipeline{{Commands: []*Command{cmd}}}})
+				}
+				i = end
+			}
+		}
+		return scripts
+	}
+
+	var scripts []*Script
+	for _, src := range sources {
+		if script, err := Parse(src); err == nil {
+			scripts = append(scripts, script)
+		}
+	}
+	return scripts
+}
+
+// findArgs returns the arguments of a "find -exec" command with the words
+// containing "{}", which find replaces with the paths it found, marked
+// Dynamic.
+func findArgs(args []*Word) []*Word {
+	out := make([]*Word, len(args))
+	for i, arg := range args {
+		out[i] = arg
+		if strings.Contains(arg.Value, "{}") {
+			dynamic := *arg
+			dynamic.Expansion = true
+			out[i] = &dynamic
+		}
+	}
+	return out
+}
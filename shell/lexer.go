@@ -0,0 +1,539 @@
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokOp
+	tokRedirect
+	tokNewline
+)
+
+type token struct {
+	kind tokenKind
+	op   string
+	fd   int
+	word *Word
+	pos  int
+}
+
+// SyntaxError reports a malformed command line.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("shell syntax error at offset %d: %s", e.Pos, e.Msg)
+}
+
+type pendingHeredoc struct {
+	redirect  *Redirect
+	delimiter string
+	stripTabs bool
+}
+
+type lexer struct {
+	src      string
+	pos      int
+	heredocs []pendingHeredoc
+	depth    int
+	// inDouble is set inside double quotes, where single quotes are
+	// literal.
+	inDouble bool
+	// err is the first error, returned from then on: the position is
+	// unreliable after it.
+	err error
+}
+
+const maxDepth = 64
+
+var operators = []string{
+	";;&", "&&", "||", ";;", ";&", "|&", "|", "&", ";", "(", ")",
+}
+
+var redirects = []string{
+	"&>>", "<<<", "<<-", "&>", ">>", ">|", "<<", "<&", ">&", "<>", "<", ">",
+}
+
+func (l *lexer) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Pos: l.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) next(p *parser) (token, error) {
+	if l.err != nil {
+		return token{}, l.err
+	}
+	tok, err := l.scan(p)
+	if err != nil {
+		l.err = err
+	}
+	return tok, err
+}
+
+func (l *lexer) scan(p *parser) (token, error) {
+	l.skipBlanks()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+
+	if c == '\n' {
+		l.pos++
+		if err := l.readHeredocs(); err != nil {
+			return token{}, err
+		}
+		return token{kind: tokNewline, pos: start}, nil
+	}
+
+	if c == '#' {
+		for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+			l.pos++
+		}
+		return l.scan(p)
+	}
+
+	// Process substitution is a word even though it starts with < or >.
+	if (c == '<' || c == '>') && strings.HasPrefix(l.src[l.pos+1:], "(") {
+		return l.readWord(p)
+	}
+
+	for _, op := range redirects {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokRedirect, op: op, fd: -1, pos: start}, nil
+		}
+	}
+
+	if strings.HasPrefix(l.src[l.pos:], "((") {
+		return l.readArithmetic(p)
+	}
+
+	for _, op := range operators {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokOp, op: op, pos: start}, nil
+		}
+	}
+
+	// A run of digits directly followed by a redirection is a descriptor.
+	end := l.pos
+	for end < len(l.src) && l.src[end] >= '0' && l.src[end] <= '9' {
+		end++
+	}
+	if end > l.pos && end < len(l.src) && (l.src[end] == '<' || l.src[end] == '>') {
+		fd, _ := strconv.Atoi(l.src[l.pos:end])
+		l.pos = end
+		for _, op := range redirects {
+			if strings.HasPrefix(l.src[l.pos:], op) {
+				l.pos += len(op)
+				return token{kind: tokRedirect, op: op, fd: fd, pos: start}, nil
+			}
+		}
+	}
+
+	return l.readWord(p)
+}
+
+func (l *lexer) skipBlanks() {
+	for l.pos < len(l.src) {
+		switch {
+		case l.src[l.pos] == ' ' || l.src[l.pos] == '\t' || l.src[l.pos] == '\r':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "\\\n"):
+			l.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+func isMeta(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', ';', '&', '|', '(', ')', '<', '>':
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *lexer) readWord(p *parser) (token, error) {
+	start := l.pos
+	w := &Word{}
+	var value strings.Builder
+	// Unquoted braces holding a comma or ".." are brace expansions.
+	braces, braceList := 0, false
+
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+
+		if (c == '<' || c == '>') && strings.HasPrefix(l.src[l.pos+1:], "(") {
+			open := l.pos
+			l.pos += 2
+			script, err := l.parseSubstitution(p)
+			if err != nil {
+				return token{}, err
+			}
+			w.Substitutions = append(w.Substitutions, script)
+			w.Expansion = true
+			value.WriteString(l.src[open:l.pos])
+			continue
+		}
+		if isMeta(c) {
+			break
+		}
+
+		switch c {
+		case '\\':
+			if l.pos+1 >= len(l.src) {
+				l.pos++
+				continue
+			}
+			if l.src[l.pos+1] == '\n' {
+				l.pos += 2
+				continue
+			}
+			w.Quoted = true
+			value.WriteByte(l.src[l.pos+1])
+			l.pos += 2
+		case '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return token{}, l.errorf("unterminated single quote")
+			}
+			w.Quoted = true
+			value.WriteString(l.src[l.pos+1 : l.pos+1+end])
+			l.pos += end + 2
+		case '"':
+			if err := l.readDoubleQuoted(p, w, &value); err != nil {
+				return token{}, err
+			}
+		case '`':
+			if err := l.readBackquote(w, &value); err != nil {
+				return token{}, err
+			}
+		case '$':
+			if err := l.readDollar(p, w, &value); err != nil {
+				return token{}, err
+			}
+		case '*', '?', '[':
+			w.Glob = true
+			value.WriteByte(c)
+			l.pos++
+		case '{', '}', ',', '.':
+			switch {
+			case c == '{':
+				braces++
+			case c == '}' && braces > 0:
+				braces--
+				w.Brace = w.Brace || braceList
+			case braces > 0 && (c == ',' || strings.HasPrefix(l.src[l.pos:], "..")):
+				braceList = true
+			}
+			value.WriteByte(c)
+			l.pos++
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	w.Raw = l.src[start:l.pos]
+	w.Value = value.String()
+	return token{kind: tokWord, word: w, pos: start}, nil
+}
+
+func (l *lexer) readDoubleQuoted(p *parser, w *Word, value *strings.Builder) error {
+	w.Quoted = true
+	l.pos++
+	inDouble := l.inDouble
+	l.inDouble = true
+	defer func() { l.inDouble = inDouble }()
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return nil
+		case '\\':
+			if l.pos+1 < len(l.src) {
+				switch next := l.src[l.pos+1]; next {
+				case '$', '`', '"', '\\':
+					value.WriteByte(next)
+					l.pos += 2
+					continue
+				case '\n':
+					l.pos += 2
+					continue
+				}
+			}
+			value.WriteByte(c)
+			l.pos++
+		case '`':
+			if err := l.readBackquote(w, value); err != nil {
+				return err
+			}
+		case '$':
+			if err := l.readDollar(p, w, value); err != nil {
+				return err
+			}
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+	return l.errorf("unterminated double quote")
+}
+
+func (l *lexer) readBackquote(w *Word, value *strings.Builder) error {
+	start := l.pos
+	l.pos++
+	var inner strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '`' {
+			l.pos++
+			script, err := parseDepth(inner.String(), l.depth+1)
+			if err != nil {
+				return err
+			}
+			w.Substitutions = append(w.Substitutions, script)
+			w.Expansion = true
+			value.WriteString(l.src[start:l.pos])
+			return nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			switch next := l.src[l.pos+1]; next {
+			case '`', '$', '\\':
+				inner.WriteByte(next)
+				l.pos += 2
+				continue
+			}
+		}
+		inner.WriteByte(c)
+		l.pos++
+	}
+	return l.errorf("unterminated backquote")
+}
+
+func (l *lexer) readDollar(p *parser, w *Word, value *strings.Builder) error {
+	start := l.pos
+	rest := l.src[l.pos+1:]
+
+	switch {
+	case strings.HasPrefix(rest, "(("):
+		l.pos += 3
+		if err := l.readExpansion(p, w, true); err != nil {
+			return err
+		}
+		w.Expansion = true
+	case strings.HasPrefix(rest, "("):
+		l.pos += 2
+		script, err := l.parseSubstitution(p)
+		if err != nil {
+			return err
+		}
+		w.Substitutions = append(w.Substitutions, script)
+		w.Expansion = true
+	case strings.HasPrefix(rest, "{"):
+		l.pos += 2
+		if err := l.readExpansion(p, w, false); err != nil {
+			return err
+		}
+		w.Expansion = true
+	case strings.HasPrefix(rest, "'"):
+		return l.readANSIC(w, value)
+	case len(rest) > 0 && isSpecialParam(rest[0]):
+		l.pos += 2
+		w.Expansion = true
+	case len(rest) > 0 && isNameStart(rest[0]):
+		l.pos++
+		for l.pos < len(l.src) && isNameChar(l.src[l.pos]) {
+			l.pos++
+		}
+		w.Expansion = true
+	default:
+		l.pos++
+	}
+
+	value.WriteString(l.src[start:l.pos])
+	return nil
+}
+
+// parseSubstitution parses the script of a command or process substitution,
+// in which quoting starts over.
+func (l *lexer) parseSubstitution(p *parser) (*Script, error) {
+	inDouble := l.inDouble
+	l.inDouble = false
+	defer func() { l.inDouble = inDouble }()
+	return p.parseNested(")")
+}
+
+// readExpansion scans the body of a parameter expansion up to its closing
+// "}", or of an arithmetic expansion up to its closing "))", following
+// nested expansions and quotes. Command substitutions inside it are added
+// to w, since they run whatever the expansion's value.
+func (l *lexer) readExpansion(p *parser, w *Word, arithmetic bool) error {
+	quoted := w.Quoted
+	defer func() { w.Quoted = quoted }()
+	var discard strings.Builder
+	parens := 0
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		var err error
+		switch {
+		case c == '\\':
+			l.pos += 2
+		case c == '\'' && !l.inDouble && !arithmetic:
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return l.errorf("unterminated single quote")
+			}
+			l.pos += end + 2
+		case c == '"':
+			err = l.readDoubleQuoted(p, w, &discard)
+		case c == '`':
+			err = l.readBackquote(w, &discard)
+		case c == '$':
+			err = l.readDollar(p, w, &discard)
+		case arithmetic && c == '(':
+			parens++
+			l.pos++
+		case arithmetic && c == ')':
+			if parens > 0 {
+				parens--
+				l.pos++
+				continue
+			}
+			if !strings.HasPrefix(l.src[l.pos:], "))") {
+				return l.errorf("unbalanced parenthesis in arithmetic expansion")
+			}
+			l.pos += 2
+			return nil
+		case !arithmetic && c == '}':
+			l.pos++
+			return nil
+		default:
+			l.pos++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if arithmetic {
+		return l.errorf("unterminated arithmetic expansion")
+	}
+	return l.errorf("unterminated parameter expansion")
+}
+
+func (l *lexer) readANSIC(w *Word, value *strings.Builder) error {
+	w.Quoted = true
+	l.pos += 2
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '\'' {
+			l.pos++
+			return nil
+		}
+		if c == '\\' && l.pos+1 < len(l.src) {
+			next := l.src[l.pos+1]
+			l.pos += 2
+			switch next {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case '0':
+				value.WriteByte(0)
+			case 'e', 'E':
+				value.WriteByte(0x1b)
+			case 'x':
+				n := 0
+				for n < 2 && l.pos+n < len(l.src) && isHex(l.src[l.pos+n]) {
+					n++
+				}
+				if b, err := strconv.ParseUint(l.src[l.pos:l.pos+n], 16, 8); err == nil {
+					value.WriteByte(byte(b))
+				}
+				l.pos += n
+			default:
+				value.WriteByte(next)
+			}
+			continue
+		}
+		value.WriteByte(c)
+		l.pos++
+	}
+	return l.errorf("unterminated $'...' string")
+}
+
+func (l *lexer) readArithmetic(p *parser) (token, error) {
+	start := l.pos
+	l.pos += 2
+	w := &Word{Expansion: true}
+	if err := l.readExpansion(p, w, true); err != nil {
+		return token{}, err
+	}
+	w.Raw = l.src[start:l.pos]
+	w.Value = w.Raw
+	return token{kind: tokWord, word: w, pos: start}, nil
+}
+
+func (l *lexer) readHeredocs() error {
+	for _, h := range l.heredocs {
+		var body strings.Builder
+		for {
+			if l.pos >= len(l.src) {
+				h.redirect.Heredoc = body.String()
+				break
+			}
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			var line string
+			if end < 0 {
+				line = l.src[l.pos:]
+				l.pos = len(l.src)
+			} else {
+				line = l.src[l.pos : l.pos+end]
+				l.pos += end + 1
+			}
+			if h.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == h.delimiter {
+				h.redirect.Heredoc = body.String()
+				break
+			}
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+	}
+	l.heredocs = nil
+	return nil
+}
+
+func isSpecialParam(c byte) bool {
+	return strings.IndexByte("@*#?-$!0123456789", c) >= 0
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,443 @@
+package shell
+
+import "strings"
+
+// Parse parses a Bash command line.
+func Parse(src string) (*Script, error) {
+	return parseDepth(src, 0)
+}
+
+func parseDepth(src string, depth int) (*Script, error) {
+	l := &lexer{src: src, depth: depth}
+	if depth > maxDepth {
+		return nil, l.errorf("nesting too deep")
+	}
+	p := &parser{lex: l}
+
+	script, err := p.parseList(nil)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind != tokEOF {
+		return nil, p.unexpected(tok)
+	}
+	return script, nil
+}
+
+type parser struct {
+	lex    *lexer
+	tok    token
+	peeked bool
+}
+
+var reservedPrefixes = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "fi": true,
+	"while": true, "until": true, "do": true, "done": true,
+	"esac": true,
+}
+
+func (p *parser) peek() (token, error) {
+	if !p.peeked {
+		tok, err := p.lex.next(p)
+		if err != nil {
+			return token{}, err
+		}
+		p.tok = tok
+		p.peeked = true
+	}
+	return p.tok, nil
+}
+
+func (p *parser) advance() {
+	p.peeked = false
+}
+
+func (p *parser) unexpected(tok token) error {
+	switch tok.kind {
+	case tokEOF:
+		return &SyntaxError{Pos: tok.pos, Msg: "unexpected end of input"}
+	case tokNewline:
+		return &SyntaxError{Pos: tok.pos, Msg: "unexpected newline"}
+	case tokWord:
+		return &SyntaxError{Pos: tok.pos, Msg: "unexpected word " + tok.word.Raw}
+	default:
+		return &SyntaxError{Pos: tok.pos, Msg: "unexpected " + tok.op}
+	}
+}
+
+// parseNested parses a list terminated by the closing operator, as used by
+// command and process substitution. The lexer is shared with the outer
+// parser so parsing resumes right after the terminator.
+func (p *parser) parseNested(closing string) (*Script, error) {
+	if p.lex.depth >= maxDepth {
+		return nil, p.lex.errorf("nesting too deep")
+	}
+	p.lex.depth++
+	defer func() { p.lex.depth-- }()
+
+	nested := &parser{lex: p.lex}
+	script, err := nested.parseList(func(tok token) bool {
+		return tok.kind == tokOp && tok.op == closing
+	})
+	if err != nil {
+		return nil, err
+	}
+	tok, err := nested.peek()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind != tokOp || tok.op != closing {
+		return nil, nested.unexpected(tok)
+	}
+	return script, nil
+}
+
+func isWord(tok token, value string) bool {
+	return tok.kind == tokWord && !tok.word.Quoted && tok.word.Raw == value
+}
+
+func (p *parser) parseList(stop func(token) bool) (*Script, error) {
+	script := &Script{}
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokEOF || (stop != nil && stop(tok)) {
+			return script, nil
+		}
+		if tok.kind == tokNewline || (tok.kind == tokOp && (tok.op == ";" || tok.op == "&")) {
+			p.advance()
+			continue
+		}
+		if tok.kind == tokWord && !tok.word.Quoted && reservedPrefixes[tok.word.Raw] {
+			p.advance()
+			continue
+		}
+
+		pipeline, err := p.parsePipeline(stop)
+		if err != nil {
+			return nil, err
+		}
+		script.Pipelines = append(script.Pipelines, pipeline)
+
+		tok, err = p.peek()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case tok.kind == tokOp && (tok.op == "&&" || tok.op == "||"):
+			pipeline.Op = tok.op
+			p.advance()
+			if err := p.skipNewlines(); err != nil {
+				return nil, err
+			}
+		case tok.kind == tokOp && (tok.op == ";" || tok.op == "&"):
+			pipeline.Op = tok.op
+			p.advance()
+		case tok.kind == tokNewline:
+			pipeline.Op = ";"
+			p.advance()
+		case tok.kind == tokEOF || (stop != nil && stop(tok)):
+		case tok.kind == tokWord && !tok.word.Quoted && reservedPrefixes[tok.word.Raw]:
+			// "done", "fi" and friends close a compound command.
+		default:
+			return nil, p.unexpected(tok)
+		}
+	}
+}
+
+func (p *parser) skipNewlines() error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind != tokNewline {
+			return nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) parsePipeline(stop func(token) bool) (*Pipeline, error) {
+	pipeline := &Pipeline{}
+
+	tok, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if isWord(tok, "!") {
+		pipeline.Negated = true
+		p.advance()
+	}
+
+	for {
+		cmd, err := p.parseCommand(stop)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Commands = append(pipeline.Commands, cmd)
+
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != tokOp || (tok.op != "|" && tok.op != "|&") {
+			return pipeline, nil
+		}
+		p.advance()
+		if err := p.skipNewlines(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseCommand(stop func(token) bool) (*Command, error) {
+	tok, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok.kind == tokOp && tok.op == "(" {
+		p.advance()
+		body, err := p.parseList(func(tok token) bool { return tok.kind == tokOp && tok.op == ")" })
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOp(")"); err != nil {
+			return nil, err
+		}
+		cmd := &Command{Subshell: true, Body: []*Script{body}}
+		return cmd, p.parseRedirects(cmd)
+	}
+
+	if isWord(tok, "{") {
+		p.advance()
+		body, err := p.parseList(func(tok token) bool { return isWord(tok, "}") })
+		if err != nil {
+			return nil, err
+		}
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !isWord(tok, "}") {
+			return nil, p.unexpected(tok)
+		}
+		p.advance()
+		cmd := &Command{Body: []*Script{body}}
+		return cmd, p.parseRedirects(cmd)
+	}
+
+	if isWord(tok, "case") {
+		return p.parseCase()
+	}
+
+	cmd := &Command{}
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.kind {
+		case tokWord:
+			if stop != nil && stop(tok) {
+				return p.finishSimple(cmd, tok)
+			}
+			p.advance()
+			if len(cmd.Args) == 0 && isAssignment(tok.word) {
+				cmd.Assignments = append(cmd.Assignments, tok.word)
+				continue
+			}
+			cmd.Args = append(cmd.Args, tok.word)
+
+			if len(cmd.Args) == 1 && len(cmd.Assignments) == 0 {
+				if next, err := p.peek(); err == nil && next.kind == tokOp && next.op == "(" {
+					return p.parseFunction(cmd)
+				}
+			}
+		case tokRedirect:
+			if err := p.parseRedirect(cmd, tok); err != nil {
+				return nil, err
+			}
+		default:
+			return p.finishSimple(cmd, tok)
+		}
+	}
+}
+
+func (p *parser) finishSimple(cmd *Command, tok token) (*Command, error) {
+	if len(cmd.Args) == 0 && len(cmd.Assignments) == 0 && len(cmd.Redirects) == 0 {
+		return nil, p.unexpected(tok)
+	}
+	return cmd, nil
+}
+
+func (p *parser) parseFunction(cmd *Command) (*Command, error) {
+	p.advance()
+	if err := p.expectOp(")"); err != nil {
+		return nil, err
+	}
+	if err := p.skipNewlines(); err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseCommand(nil)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Function = true
+	cmd.Body = []*Script{{Pipelines: []*Pipeline{{Commands: []*Command{body}}}}}
+	return cmd, nil
+}
+
+func (p *parser) parseCase() (*Command, error) {
+	cmd := &Command{}
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind != tokWord {
+			return nil, p.unexpected(tok)
+		}
+		p.advance()
+		cmd.Args = append(cmd.Args, tok.word)
+		if isWord(tok, "in") {
+			break
+		}
+	}
+
+	for {
+		if err := p.skipSeparators(); err != nil {
+			return nil, err
+		}
+		tok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if isWord(tok, "esac") {
+			p.advance()
+			return cmd, p.parseRedirects(cmd)
+		}
+
+		// Pattern list: [(] pattern [| pattern]... )
+		for {
+			tok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if tok.kind == tokEOF {
+				return nil, p.unexpected(tok)
+			}
+			p.advance()
+			if tok.kind == tokOp && tok.op == ")" {
+				break
+			}
+		}
+
+		body, err := p.parseList(func(tok token) bool {
+			return (tok.kind == tokOp && strings.HasPrefix(tok.op, ";;")) ||
+				(tok.kind == tokOp && tok.op == ";&") || isWord(tok, "esac")
+		})
+		if err != nil {
+			return nil, err
+		}
+		cmd.Body = append(cmd.Body, body)
+
+		tok, err = p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if tok.kind == tokOp {
+			p.advance()
+		}
+	}
+}
+
+func (p *parser) skipSeparators() error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind != tokNewline && !(tok.kind == tokOp && tok.op == ";") {
+			return nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) parseRedirects(cmd *Command) error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if tok.kind != tokRedirect {
+			return nil
+		}
+		if err := p.parseRedirect(cmd, tok); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseRedirect(cmd *Command, tok token) error {
+	p.advance()
+	target, err := p.peek()
+	if err != nil {
+		return err
+	}
+	if target.kind != tokWord {
+		return p.unexpected(target)
+	}
+	p.advance()
+
+	redirect := &Redirect{FD: tok.fd, Op: tok.op, Target: target.word}
+	cmd.Redirects = append(cmd.Redirects, redirect)
+
+	if tok.op == "<<" || tok.op == "<<-" {
+		p.lex.heredocs = append(p.lex.heredocs, pendingHeredoc{
+			redirect:  redirect,
+			delimiter: target.word.Value,
+			stripTabs: tok.op == "<<-",
+		})
+	}
+	return nil
+}
+
+func (p *parser) expectOp(op string) error {
+	tok, err := p.peek()
+	if err != nil {
+		return err
+	}
+	if tok.kind != tokOp || tok.op != op {
+		return p.unexpected(tok)
+	}
+	p.advance()
+	return nil
+}
+
+func isAssignment(w *Word) bool {
+	name, _, ok := strings.Cut(w.Raw, "=")
+	if !ok || name == "" {
+		return false
+	}
+	name = strings.TrimSuffix(name, "+")
+	if !isNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
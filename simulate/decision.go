@@ -0,0 +1,99 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/handler"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DecisionStop is reported for output with "continue": false, which ends the
+// agent's turn whatever the event.
+const DecisionStop = "stop"
+
+// Interpret reads a hook's decision from its exit code and output: exit
+// code 2 blocks with stderr as the reason, ignoring stdout as the host
+// does, other non-zero codes are non-blocking errors, and exit code 0 may
+// carry a JSON decision on stdout.
+// Decisions are reported as in handler.Outcome, plus DecisionStop.
+func Interpret(event types.EventName, r Result) (decision, reason string) {
+	switch {
+	case r.TimedOut:
+		return handler.OutcomeError, fmt.Sprintf("timed out after %v", r.Hook.Timeout())
+	case r.Error != "":
+		return handler.OutcomeError, r.Error
+	case r.ExitCode == types.ExitBlocking:
+		// The host ignores stdout on this exit code.
+		reason := strings.TrimSpace(r.Stderr)
+		switch event {
+		case types.EventPreToolUse:
+			return string(types.PermissionDeny), reason
+		case types.EventPostToolUse, types.EventUserPromptSubmit, types.EventStop, types.EventSubagentStop:
+			return handler.OutcomeBlock, reason
+		}
+		// The host only shows stderr to the user for the other events.
+		return handler.OutcomeContinue, reason
+	case r.ExitCode != 0:
+		return handler.OutcomeError, strings.TrimSpace(r.Stderr)
+	}
+	return interpretJSON(event, r.Stdout)
+}
+
+func interpretJSON(event types.EventName, stdout string) (decision, reason string) {
+	stdout = strings.TrimSpace(stdout)
+	if !strings.HasPrefix(stdout, "{") {
+		return handler.OutcomeContinue, ""
+	}
+	var out struct {
+		Continue           *bool  `json:"continue"`
+		StopReason         string `json:"stopReason"`
+		Decision           string `json:"decision"`
+		Reason             string `json:"reason"`
+		HookSpecificOutput struct {
+			PermissionDecision       string `json:"permissionDecision"`
+			PermissionDecisionReason string `json:"permissionDecisionReason"`
+		} `json:"hookSpecificOutput"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		return handler.OutcomeContinue, ""
+	}
+	switch {
+	case out.Continue != nil && !*out.Continue:
+		return DecisionStop, out.StopReason
+	case event == types.EventPreToolUse && out.HookSpecificOutput.PermissionDecision != "":
+		return out.HookSpecificOutput.PermissionDecision, out.HookSpecificOutput.PermissionDecisionReason
+	case out.Decision == "block":
+		if event == types.EventPreToolUse {
+			return string(types.PermissionDeny), out.Reason
+		}
+		return handler.OutcomeBlock, out.Reason
+	case out.Decision == "approve" && event == types.EventPreToolUse:
+		return string(types.PermissionAllow), out.Reason
+	}
+	return handler.OutcomeContinue, ""
+}
+
+// precedence orders decisions when hooks disagree; errors do not block.
+var precedence = map[string]int{
+	handler.OutcomeError:          0,
+	handler.OutcomeContinue:       1,
+	string(types.PermissionAllow): 2,
+	string(types.PermissionAsk):   3,
+	string(types.PermissionDeny):  4,
+	handler.OutcomeBlock:          4,
+	DecisionStop:                  5,
+}
+
+// Combine returns the decision the host acts on: the most restrictive one,
+// with the reason of the first hook that made it.
+func Combine(results []Result) (decision, reason string) {
+	decision = handler.OutcomeContinue
+	for _, r := range results {
+		if precedence[r.Decision] > precedence[decision] {
+			decision, reason = r.Decision, r.Reason
+		}
+	}
+	return decision, reason
+}
@@ -0,0 +1,145 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Event describes a payload to send to hooks. Fields that do not apply to
+// the event are ignored.
+type Event struct {
+	Name           types.EventName
+	SessionID      string
+	TranscriptPath string
+	CWD            string
+
+	ToolName     types.ToolName
+	ToolInput    map[string]interface{}
+	ToolResponse interface{}
+
+	Prompt         string
+	Message        string
+	Trigger        types.CompactTrigger
+	Source         types.SessionSource
+	StopHookActive bool
+}
+
+// Payload renders the event as the JSON the host writes to a hook's stdin.
+func (e Event) Payload() ([]byte, error) {
+	base := types.BaseInput{
+		SessionID:      e.SessionID,
+		TranscriptPath: e.TranscriptPath,
+		CWD:            e.CWD,
+		HookEventName:  string(e.Name),
+	}
+	toolInput := e.ToolInput
+	if toolInput == nil {
+		toolInput = map[string]interface{}{}
+	}
+
+	var input interface{}
+	switch e.Name {
+	case types.EventPreToolUse:
+		input = types.PreToolUseInput{BaseInput: base, ToolName: e.ToolName, ToolInput: toolInput}
+	case types.EventPostToolUse:
+		input = types.PostToolUseInput{BaseInput: base, ToolName: e.ToolName, ToolInput: toolInput, ToolResponse: e.ToolResponse}
+	case types.EventNotification:
+		input = types.NotificationInput{BaseInput: base, Message: e.Message}
+	case types.EventUserPromptSubmit:
+		input = types.UserPromptSubmitInput{BaseInput: base, Prompt: e.Prompt}
+	case types.EventStop:
+		input = types.StopInput{BaseInput: base, StopHookActive: e.StopHookActive}
+	case types.EventSubagentStop:
+		input = types.SubagentStopInput{BaseInput: base, StopHookActive: e.StopHookActive}
+	case types.EventPreCompact:
+		input = types.PreCompactInput{BaseInput: base, Trigger: e.Trigger}
+	case types.EventSessionStart:
+		input = types.SessionStartInput{BaseInput: base, Source: e.Source}
+	default:
+		return nil, fmt.Errorf("unknown event %q", e.Name)
+	}
+	return json.Marshal(input)
+}
+
+var probeTools = []types.ToolName{
+	types.ToolRead, types.ToolGlob, types.ToolGrep, types.ToolBash, types.ToolWrite,
+	types.ToolEdit, types.ToolMultiEdit, types.ToolWebFetch, types.ToolWebSearch, types.ToolTask,
+}
+
+// Synthetic returns a harmless event for a hook configured with matcher,
+// choosing a tool, trigger or source the matcher accepts. ok is false when
+// no known value matches, in which case the event would not reach the hook.
+func Synthetic(event types.EventName, matcher, cwd string) (e Event, ok bool) {
+	e = Event{
+		Name:           event,
+		SessionID:      "hookctl-doctor",
+		TranscriptPath: filepath.Join(cwd, ".hookctl-doctor.jsonl"),
+		CWD:            cwd,
+		Prompt:         "hello",
+		Message:        "hookctl doctor",
+		Trigger:        types.CompactTriggerManual,
+		Source:         types.SessionSourceStartup,
+	}
+	switch event {
+	case types.EventPreToolUse, types.EventPostToolUse:
+		candidates := probeTools
+		if matcher != "" && matcher != "*" && Matches(matcher, matcher) {
+			// A literal matcher such as an MCP tool name.
+			candidates = append([]types.ToolName{types.ToolName(matcher)}, candidates...)
+		}
+		for _, tool := range candidates {
+			if Matches(matcher, string(tool)) {
+				e.ToolName = tool
+				e.ToolInput = probeInput(tool, cwd)
+				if event == types.EventPostToolUse {
+					e.ToolResponse = map[string]interface{}{"success": true}
+				}
+				return e, true
+			}
+		}
+		return e, false
+	case types.EventPreCompact:
+		for _, trigger := range []types.CompactTrigger{types.CompactTriggerManual, types.CompactTriggerAuto} {
+			if Matches(matcher, string(trigger)) {
+				e.Trigger = trigger
+				return e, true
+			}
+		}
+		return e, false
+	case types.EventSessionStart:
+		for _, source := range []types.SessionSource{types.SessionSourceStartup, types.SessionSourceResume, types.SessionSourceClear} {
+			if Matches(matcher, string(source)) {
+				e.Source = source
+				return e, true
+			}
+		}
+		return e, false
+	}
+	return e, true
+}
+
+func probeInput(tool types.ToolName, cwd string) map[string]interface{} {
+	file := filepath.Join(cwd, "README.md")
+	switch tool {
+	case types.ToolBash:
+		return map[string]interface{}{"command": "echo hello"}
+	case types.ToolRead:
+		return map[string]interface{}{"file_path": file}
+	case types.ToolWrite:
+		return map[string]interface{}{"file_path": file, "content": "hello\n"}
+	case types.ToolEdit:
+		return map[string]interface{}{"file_path": file, "old_string": "hello", "new_string": "hello"}
+	case types.ToolMultiEdit:
+		return map[string]interface{}{"file_path": file, "edits": []interface{}{map[string]interface{}{"old_string": "hello", "new_string": "hello"}}}
+	case types.ToolGlob, types.ToolGrep:
+		return map[string]interface{}{"pattern": "hello"}
+	case types.ToolWebFetch:
+		return map[string]interface{}{"url": "https://example.com", "prompt": "summarize"}
+	case types.ToolWebSearch:
+		return map[string]interface{}{"query": "hello"}
+	}
+	return map[string]interface{}{}
+}
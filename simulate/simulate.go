@@ -0,0 +1,207 @@
+// Package simulate runs the hooks configured in settings files the way the
+// host does, so a configuration can be tested end to end without starting
+// an agent session.
+//
+// For an event it selects the configured commands whose matcher accepts the
+// payload, drops duplicate commands, runs the rest in parallel through the
+// shell with the payload on stdin and each command's timeout, and reads the
+// outcome from the exit code and stdout as the host would.
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/settings"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// DefaultTimeout applies to commands without a timeout, as in the host.
+const DefaultTimeout = 60 * time.Second
+
+// Hook is a command configured for an event.
+type Hook struct {
+	Event   types.EventName  `json:"event"`
+	Matcher string           `json:"matcher,omitempty"`
+	Command settings.Command `json:"command"`
+	// Source is the settings file the hook comes from.
+	Source string `json:"source"`
+}
+
+// Timeout returns the command's timeout, or DefaultTimeout.
+func (h Hook) Timeout() time.Duration {
+	if h.Command.Timeout > 0 {
+		return time.Duration(h.Command.Timeout) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// Configured lists the hooks of the given files in order. The host merges
+// the hooks of all its settings files rather than letting one replace
+// another.
+func Configured(files ...*settings.File) ([]Hook, error) {
+	var hooks []Hook
+	for _, f := range files {
+		configured, err := f.Hooks()
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range f.Events() {
+			for _, group := range configured[event] {
+				for _, cmd := range group.Hooks {
+					hooks = append(hooks, Hook{Event: event, Matcher: group.Matcher, Command: cmd, Source: f.Path})
+				}
+			}
+		}
+	}
+	return hooks, nil
+}
+
+// Select returns the hooks the host would run for payload: those configured
+// for its event whose matcher accepts it, without repeating a command.
+func Select(hooks []Hook, payload []byte) ([]Hook, error) {
+	var fields struct {
+		Event    types.EventName `json:"hook_event_name"`
+		ToolName string          `json:"tool_name"`
+		Trigger  string          `json:"trigger"`
+		Source   string          `json:"source"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+
+	// Tool events match the tool name, PreCompact the trigger and
+	// SessionStart the source. Other events ignore the matcher.
+	value, matched := "", true
+	switch fields.Event {
+	case types.EventPreToolUse, types.EventPostToolUse:
+		value, matched = fields.ToolName, false
+	case types.EventPreCompact:
+		value, matched = fields.Trigger, false
+	case types.EventSessionStart:
+		value, matched = fields.Source, false
+	}
+
+	var selected []Hook
+	seen := make(map[string]bool)
+	for _, h := range hooks {
+		if h.Event != fields.Event || seen[h.Command.Command] {
+			continue
+		}
+		if !matched && !Matches(h.Matcher, value) {
+			continue
+		}
+		seen[h.Command.Command] = true
+		selected = append(selected, h)
+	}
+	return selected, nil
+}
+
+// Matches reports whether a matcher accepts value. Empty and "*" match
+// everything; otherwise the matcher is a regular expression that must match
+// the whole value, so "Write" matches only Write and "Edit|Write" either.
+func Matches(matcher, value string) bool {
+	if matcher == "" || matcher == "*" {
+		return true
+	}
+	re, err := CompileMatcher(matcher)
+	if err != nil {
+		return matcher == value
+	}
+	return re.MatchString(value)
+}
+
+// CompileMatcher compiles a matcher other than "" and "*" into the
+// anchored expression Matches uses.
+func CompileMatcher(matcher string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + matcher + ")$")
+}
+
+// Result is what one hook printed and how it exited.
+type Result struct {
+	Hook     Hook          `json:"hook"`
+	Stdout   string        `json:"stdout"`
+	Stderr   string        `json:"stderr"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"-"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+	// Error reports a command that could not be started.
+	Error    string `json:"error,omitempty"`
+	Decision string `json:"decision"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type result Result
+	return json.Marshal(struct {
+		result
+		DurationMS float64 `json:"duration_ms"`
+	}{result(r), float64(r.Duration.Microseconds()) / 1000})
+}
+
+// Run executes hooks in parallel in dir with payload on stdin and returns
+// their results in the order of hooks. CLAUDE_PROJECT_DIR is set to dir.
+func Run(ctx context.Context, hooks []Hook, payload []byte, dir string) []Result {
+	results := make([]Result, len(hooks))
+	var wg sync.WaitGroup
+	for i, h := range hooks {
+		wg.Add(1)
+		go func(i int, h Hook) {
+			defer wg.Done()
+			results[i] = run(ctx, h, payload, dir)
+		}(i, h)
+	}
+	wg.Wait()
+	return results
+}
+
+func run(ctx context.Context, h Hook, payload []byte, dir string) Result {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout())
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", h.Command.Command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", h.Command.Command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CLAUDE_PROJECT_DIR="+dir)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Children of the shell may keep the pipes open after it is killed.
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{
+		Hook:     h,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.ExitCode = -1
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Error = err.Error()
+	}
+	result.Decision, result.Reason = Interpret(h.Event, result)
+	return result
+}
@@ -0,0 +1,61 @@
+package state
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying session. The router does this
+// for every invocation, so handlers reach the session through
+// input.Context().
+func NewContext(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, session)
+}
+
+// FromContext returns the session carried by ctx, bound to ctx, or nil.
+func FromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(contextKey{}).(*Session)
+	if session == nil {
+		return nil
+	}
+	return session.WithContext(ctx)
+}
+
+// Getter is implemented by Session and Tx.
+type Getter interface {
+	Get(key string, dst any) (bool, error)
+}
+
+// Lookup decodes the value stored under key as a T.
+func Lookup[T any](g Getter, key string) (T, bool, error) {
+	var v T
+	found, err := g.Get(key, &v)
+	return v, found, err
+}
+
+// Get reads key from the session carried by ctx.
+func Get[T any](ctx context.Context, key string) (T, bool, error) {
+	session := FromContext(ctx)
+	if session == nil {
+		var zero T
+		return zero, false, ErrNoSession
+	}
+	return Lookup[T](session, key)
+}
+
+// Set writes key to the session carried by ctx.
+func Set[T any](ctx context.Context, key string, value T) error {
+	session := FromContext(ctx)
+	if session == nil {
+		return ErrNoSession
+	}
+	return session.Set(key, value)
+}
+
+// Update runs fn in a locked transaction on the session carried by ctx.
+func Update(ctx context.Context, fn func(tx *Tx) error) error {
+	session := FromContext(ctx)
+	if session == nil {
+		return ErrNoSession
+	}
+	return session.Update(fn)
+}
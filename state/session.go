@@ -0,0 +1,282 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/filelock"
+)
+
+// Session is the state of one session in one project. Its methods each open
+// the session file, so a Session is cheap to create and safe to share.
+type Session struct {
+	store *Store
+	id    string
+	cwd   string
+	ctx   context.Context
+}
+
+// WithContext returns a copy of the session whose writes wait for the
+// session's lock only until ctx is done.
+func (s *Session) WithContext(ctx context.Context) *Session {
+	c := *s
+	c.ctx = ctx
+	return &c
+}
+
+func (s *Session) ID() string {
+	return s.id
+}
+
+func (s *Session) CWD() string {
+	return s.cwd
+}
+
+// Store returns the store the session belongs to.
+func (s *Session) Store() *Store {
+	return s.store
+}
+
+// Path returns the file the session is stored in.
+func (s *Session) Path() string {
+	return s.store.sessionPath(s.cwd, s.id)
+}
+
+type entry struct {
+	Value     json.RawMessage `json:"value"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+type sessionFile struct {
+	SessionID string           `json:"session_id"`
+	CWD       string           `json:"cwd"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Values    map[string]entry `json:"values"`
+}
+
+// Tx is a view of the session's values inside View or Update.
+type Tx struct {
+	values   map[string]entry
+	now      time.Time
+	writable bool
+	dirty    bool
+}
+
+// Get decodes the value stored under key into dst. It reports false if the
+// key is absent or expired.
+func (tx *Tx) Get(key string, dst any) (bool, error) {
+	e, ok := tx.values[key]
+	if !ok || e.expired(tx.now) {
+		return false, nil
+	}
+	if err := json.Unmarshal(e.Value, dst); err != nil {
+		return true, fmt.Errorf("failed to decode state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Has reports whether key holds an unexpired value.
+func (tx *Tx) Has(key string) bool {
+	e, ok := tx.values[key]
+	return ok && !e.expired(tx.now)
+}
+
+// Set stores value under key with no expiry.
+func (tx *Tx) Set(key string, value any) error {
+	return tx.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL stores value under key until ttl has elapsed. A zero ttl never
+// expires.
+func (tx *Tx) SetWithTTL(key string, value any, ttl time.Duration) error {
+	if !tx.writable {
+		return errors.New("state: Set called in a read-only transaction")
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode state %q: %w", key, err)
+	}
+	e := entry{Value: data}
+	if ttl > 0 {
+		expires := tx.now.Add(ttl)
+		e.ExpiresAt = &expires
+	}
+	tx.values[key] = e
+	tx.dirty = true
+	return nil
+}
+
+func (tx *Tx) Delete(key string) {
+	if _, ok := tx.values[key]; ok && tx.writable {
+		delete(tx.values, key)
+		tx.dirty = true
+	}
+}
+
+// Keys returns the unexpired keys with the given prefix, sorted.
+func (tx *Tx) Keys(prefix string) []string {
+	var keys []string
+	for k, e := range tx.values {
+		if strings.HasPrefix(k, prefix) && !e.expired(tx.now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e entry) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && !now.Before(*e.ExpiresAt)
+}
+
+// View calls fn with a read-only view of the session. Writes replace the
+// session file atomically, so reads take no lock.
+func (s *Session) View(fn func(tx *Tx) error) error {
+	if s.id == "" {
+		return ErrNoSession
+	}
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	return fn(&Tx{values: file.Values, now: s.store.now()})
+}
+
+// Update calls fn with a writable view of the session while holding the
+// session's lock, and writes the result if fn changed anything and returned
+// nil.
+func (s *Session) Update(fn func(tx *Tx) error) error {
+	if s.id == "" {
+		return ErrNoSession
+	}
+	path := s.Path()
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	lock, err := filelock.Acquire(ctx, strings.TrimSuffix(path, sessionExt)+lockExt)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	tx := &Tx{values: file.Values, now: s.store.now(), writable: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if !tx.dirty {
+		return nil
+	}
+
+	for k, e := range tx.values {
+		if e.expired(tx.now) {
+			delete(tx.values, k)
+		}
+	}
+	file.SessionID = s.id
+	file.CWD = s.cwd
+	file.UpdatedAt = tx.now
+	file.Values = tx.values
+	if err := writeFile(path, file); err != nil {
+		return err
+	}
+	s.store.maybeCleanup()
+	return nil
+}
+
+func (s *Session) Get(key string, dst any) (bool, error) {
+	var found bool
+	err := s.View(func(tx *Tx) error {
+		var err error
+		found, err = tx.Get(key, dst)
+		return err
+	})
+	return found, err
+}
+
+func (s *Session) Set(key string, value any) error {
+	return s.Update(func(tx *Tx) error { return tx.Set(key, value) })
+}
+
+func (s *Session) SetWithTTL(key string, value any, ttl time.Duration) error {
+	return s.Update(func(tx *Tx) error { return tx.SetWithTTL(key, value, ttl) })
+}
+
+func (s *Session) Delete(key string) error {
+	return s.Update(func(tx *Tx) error {
+		tx.Delete(key)
+		return nil
+	})
+}
+
+// Clear removes every value of the session.
+func (s *Session) Clear() error {
+	if s.id == "" {
+		return ErrNoSession
+	}
+	err := os.Remove(s.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *Session) read() (sessionFile, error) {
+	file := sessionFile{Values: make(map[string]entry)}
+	data, err := os.ReadFile(s.Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return file, fmt.Errorf("failed to read session state: %w", err)
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return file, fmt.Errorf("failed to parse session state %s: %w", s.Path(), err)
+	}
+	if file.Values == nil {
+		file.Values = make(map[string]entry)
+	}
+	return file, nil
+}
+
+// writeFile replaces path atomically so that readers never see a partial
+// file.
+func writeFile(path string, file sessionFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode session state: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write session state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package state persists per-session values between hook invocations. Each
+// invocation is a separate process, so anything a handler wants to remember
+// from one event to the next has to go through the file system.
+//
+// Values are scoped by project directory and session ID, stored as one JSON
+// file per session and updated under an exclusive file lock so that
+// concurrent hook processes do not lose each other's writes.
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DirEnv overrides the directory used by Default.
+const DirEnv = "CLAUDE_HOOKS_STATE_DIR"
+
+const (
+	DefaultTTL      = 24 * time.Hour
+	cleanupInterval = time.Hour
+	cleanupMarker   = ".last-cleanup"
+	sessionExt      = ".json"
+	lockExt         = ".lock"
+)
+
+var ErrNoSession = errors.New("no session ID")
+
+// Store is a directory of session files.
+type Store struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewStore returns a store rooted at dir. Nothing is created until the first
+// write.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, ttl: DefaultTTL, now: time.Now}
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore *Store
+)
+
+// Default returns the store rooted at DefaultDir.
+func Default() *Store {
+	defaultOnce.Do(func() {
+		defaultStore = NewStore(DefaultDir())
+	})
+	return defaultStore
+}
+
+// DefaultDir returns $CLAUDE_HOOKS_STATE_DIR if set, otherwise a directory
+// under the user cache directory, falling back to the temp directory.
+func DefaultDir() string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "claude-code-hooks", "state")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("claude-code-hooks-%d", os.Getuid()), "state")
+}
+
+// WithTTL sets how long a session file is kept after its last write.
+// Cleanup removes older files.
+func (s *Store) WithTTL(ttl time.Duration) *Store {
+	s.ttl = ttl
+	return s
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Session returns the session identified by sessionID in the project at cwd.
+// It does no I/O.
+func (s *Store) Session(cwd, sessionID string) *Session {
+	return &Session{store: s, id: sessionID, cwd: cwd}
+}
+
+// Cleanup removes session files not written within the store's TTL, along
+// with their lock files and any project directories left empty.
+func (s *Store) Cleanup() error {
+	projects, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	cutoff := s.now().Add(-s.ttl)
+	var errs []error
+	for _, project := range projects {
+		if !project.IsDir() {
+			continue
+		}
+		projectDir := filepath.Join(s.dir, project.Name())
+		files, err := os.ReadDir(projectDir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		remaining := len(files)
+		for _, file := range files {
+			name := file.Name()
+			if !strings.HasSuffix(name, sessionExt) {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil || info.ModTime().After(cutoff) {
+				continue
+			}
+			path := filepath.Join(projectDir, name)
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+				continue
+			}
+			remaining--
+			lockPath := strings.TrimSuffix(path, sessionExt) + lockExt
+			if err := os.Remove(lockPath); err == nil {
+				remaining--
+			}
+		}
+		if remaining == 0 {
+			os.Remove(projectDir)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// maybeCleanup runs Cleanup at most once per cleanupInterval across all
+// processes sharing the store, using the modification time of a marker file.
+func (s *Store) maybeCleanup() {
+	marker := filepath.Join(s.dir, cleanupMarker)
+	now := s.now()
+	if info, err := os.Stat(marker); err == nil && now.Sub(info.ModTime()) < cleanupInterval {
+		return
+	}
+	if err := os.WriteFile(marker, nil, 0o600); err != nil {
+		return
+	}
+	os.Chtimes(marker, now, now)
+	_ = s.Cleanup()
+}
+
+func (s *Store) sessionPath(cwd, sessionID string) string {
+	return filepath.Join(s.dir, projectKey(cwd), sessionKey(sessionID)+sessionExt)
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// projectKey names a project directory after the last element of cwd,
+// suffixed with a hash of the full path to keep projects apart.
+func projectKey(cwd string) string {
+	cwd = filepath.Clean(cwd)
+	base := unsafeChars.ReplaceAllString(filepath.Base(cwd), "_")
+	if base == "" || base == "." || base == "_" {
+		base = "project"
+	}
+	return base + "-" + shortHash(cwd)
+}
+
+func sessionKey(sessionID string) string {
+	if safe := unsafeChars.ReplaceAllString(sessionID, "_"); safe == sessionID && !strings.HasPrefix(sessionID, ".") {
+		return sessionID
+	}
+	return shortHash(sessionID)
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
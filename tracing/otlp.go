@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The types below mirror the OTLP/JSON encoding of
+// ExportTraceServiceRequest. IDs are hex strings and 64-bit integers are
+// decimal strings, as the OTLP JSON mapping requires.
+
+const (
+	instrumentationScope = "github.com/HeroSizy/claude-code-hooks-go-sdk"
+
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string      `json:"traceId"`
+	SpanID            string      `json:"spanId"`
+	ParentSpanID      string      `json:"parentSpanId,omitempty"`
+	Name              string      `json:"name"`
+	Kind              int         `json:"kind"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+	Attributes        []keyValue  `json:"attributes,omitempty"`
+	Status            *otlpStatus `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func newExportRequest(service string, spans []*Span) exportRequest {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		s.mu.Lock()
+		span := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        attributes(s.Attributes),
+		}
+		if !s.ParentID.IsZero() {
+			span.ParentSpanID = s.ParentID.String()
+		}
+		if s.Error != "" {
+			span.Status = &otlpStatus{Code: statusCodeError, Message: s.Error}
+		}
+		s.mu.Unlock()
+		out = append(out, span)
+	}
+
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: attributes([]Attribute{{Key: "service.name", Value: service}})},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: instrumentationScope}, Spans: out}},
+	}}}
+}
+
+func attributes(attrs []Attribute) []keyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]keyValue, 0, len(attrs))
+	for _, a := range attrs {
+		out = append(out, keyValue{Key: a.Key, Value: toAnyValue(a.Value)})
+	}
+	return out
+}
+
+func toAnyValue(v interface{}) anyValue {
+	switch v := v.(type) {
+	case string:
+		return anyValue{StringValue: &v}
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int:
+		s := strconv.FormatInt(int64(v), 10)
+		return anyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return anyValue{IntValue: &s}
+	case float64:
+		return anyValue{DoubleValue: &v}
+	case fmt.Stringer:
+		s := v.String()
+		return anyValue{StringValue: &s}
+	}
+	s := fmt.Sprint(v)
+	return anyValue{StringValue: &s}
+}
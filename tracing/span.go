@@ -0,0 +1,227 @@
+// Package tracing records spans for the work done inside a hook process and
+// exports them as OTLP JSON.
+//
+// Tracing is off unless a Tracer is attached to the context, in which case
+// Start returns a nil *Span whose methods do nothing, so instrumented code
+// costs a context lookup when disabled.
+//
+// All invocations of a session share a trace ID derived from the session
+// ID, and the PreToolUse and PostToolUse invocations of a tool call are
+// children of a tool-call span whose ID is derived from the call, so a
+// collector can stitch the separate processes back together.
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (t TraceID) IsZero() bool { return t == TraceID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+func (s SpanID) IsZero() bool { return s == SpanID{} }
+
+// SessionTraceID derives the trace ID shared by every invocation of a
+// session.
+func SessionTraceID(sessionID string) TraceID {
+	var id TraceID
+	sum := sha256.Sum256([]byte("session:" + sessionID))
+	copy(id[:], sum[:])
+	return id
+}
+
+// ToolCallSpanID derives the ID of the span covering one tool call from its
+// correlation key.
+func ToolCallSpanID(sessionID, callKey string) SpanID {
+	var id SpanID
+	sum := sha256.Sum256([]byte("tool_call:" + sessionID + ":" + callKey))
+	copy(id[:], sum[:])
+	return id
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for id.IsZero() {
+		for i := range id {
+			id[i] = byte(rand.Uint32())
+		}
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for id.IsZero() {
+		for i := range id {
+			id[i] = byte(rand.Uint32())
+		}
+	}
+	return id
+}
+
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Span is one timed operation. A nil *Span is valid and ignores every call.
+type Span struct {
+	tracer *Tracer
+
+	mu         sync.Mutex
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Name       string
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes []Attribute
+	Error      string
+	ended      bool
+}
+
+// SetAttributes records key/value pairs on the span. Values should be
+// strings, bools, integers or floats.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes = append(s.Attributes, attrs...)
+}
+
+// SetAttribute records a single key/value pair.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.SetAttributes(Attribute{Key: key, Value: value})
+}
+
+// SetError marks the span as failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Error = err.Error()
+}
+
+// SetStart moves the start time back, for work measured before the span
+// could be created.
+func (s *Span) SetStart(t time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.StartTime = t
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.EndAt(time.Now())
+}
+
+// EndAt ends the span at t and hands it to the tracer. Only the first call
+// has an effect.
+func (s *Span) EndAt(t time.Time) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = t
+	s.mu.Unlock()
+	s.tracer.record(s)
+}
+
+type tracerKey struct{}
+
+type spanKey struct{}
+
+// parent is the span new spans are children of: an open span of this
+// process, or a remote span such as the tool-call span.
+type parent struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+// NewContext returns a copy of ctx with tracing enabled through t. A nil t
+// leaves ctx unchanged.
+func NewContext(ctx context.Context, t *Tracer) context.Context {
+	if t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// FromContext returns the tracer carried by ctx, or nil.
+func FromContext(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	return t
+}
+
+// Enabled reports whether spans started from ctx are recorded.
+func Enabled(ctx context.Context) bool {
+	return FromContext(ctx) != nil
+}
+
+// WithParent makes spans started from ctx children of spanID in traceID. A
+// zero spanID starts new root spans in traceID.
+func WithParent(ctx context.Context, traceID TraceID, spanID SpanID) context.Context {
+	if !Enabled(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, spanKey{}, parent{traceID: traceID, spanID: spanID})
+}
+
+// Start begins a span named name as a child of the span in ctx, returning a
+// context carrying the new span. Without a tracer it returns ctx and nil.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return start(ctx, name, SpanID{})
+}
+
+// StartWithID is Start with a caller-chosen span ID, for spans that other
+// processes refer to, such as the tool-call span.
+func StartWithID(ctx context.Context, name string, id SpanID) (context.Context, *Span) {
+	return start(ctx, name, id)
+}
+
+func start(ctx context.Context, name string, id SpanID) (context.Context, *Span) {
+	t := FromContext(ctx)
+	if t == nil {
+		return ctx, nil
+	}
+	if id.IsZero() {
+		id = newSpanID()
+	}
+	span := &Span{tracer: t, SpanID: id, Name: name, StartTime: time.Now()}
+	if p, ok := ctx.Value(spanKey{}).(parent); ok {
+		span.TraceID = p.traceID
+		span.ParentID = p.spanID
+	}
+	if span.TraceID.IsZero() {
+		span.TraceID = newTraceID()
+	}
+	return context.WithValue(ctx, spanKey{}, parent{traceID: span.TraceID, spanID: span.SpanID}), span
+}
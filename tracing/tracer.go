@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/internal/filelock"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// FileEnv names the trace file used by FromEnv.
+const FileEnv = "CLAUDE_HOOKS_TRACE_FILE"
+
+// Tracer collects the spans ended in this process and appends them to a
+// file as one OTLP JSON ExportTraceServiceRequest per line, the format read
+// by the OpenTelemetry Collector's otlpjsonfile receiver. Spans are written
+// by Flush, which runs automatically before types.OutputAndExit or
+// handler.Execute exit the process.
+type Tracer struct {
+	path    string
+	service string
+	onError func(error)
+
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func NewTracer(path string) *Tracer {
+	t := &Tracer{
+		path:    path,
+		service: filepath.Base(os.Args[0]),
+		onError: func(err error) {
+			fmt.Fprintf(os.Stderr, "tracing: %v\n", err)
+		},
+	}
+	types.OnExit(func() {
+		if err := t.Flush(); err != nil {
+			t.onError(err)
+		}
+	})
+	return t
+}
+
+// FromEnv returns a tracer for $CLAUDE_HOOKS_TRACE_FILE, or nil if the
+// variable is unset.
+func FromEnv() *Tracer {
+	path := os.Getenv(FileEnv)
+	if path == "" {
+		return nil
+	}
+	return NewTracer(path)
+}
+
+// WithServiceName sets the service.name resource attribute. The default is
+// the executable name.
+func (t *Tracer) WithServiceName(name string) *Tracer {
+	t.service = name
+	return t
+}
+
+// WithErrorHandler sets the function receiving errors from the automatic
+// flush at exit. The default writes them to stderr.
+func (t *Tracer) WithErrorHandler(fn func(error)) *Tracer {
+	t.onError = fn
+	return t
+}
+
+func (t *Tracer) Path() string {
+	return t.path
+}
+
+func (t *Tracer) record(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = append(t.spans, s)
+}
+
+// Flush appends the ended spans to the trace file.
+func (t *Tracer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.spans) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(newExportRequest(t.service, t.spans))
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+	lock, err := filelock.Acquire(context.Background(), t.path+".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	t.spans = nil
+	return nil
+}
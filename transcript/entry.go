@@ -0,0 +1,178 @@
+// Package transcript reads the JSON Lines session transcripts referenced by
+// BaseInput.TranscriptPath.
+package transcript
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	TypeUser      = "user"
+	TypeAssistant = "assistant"
+	TypeSummary   = "summary"
+	TypeSystem    = "system"
+)
+
+// Entry is one line of a transcript. Fields that do not apply to an entry's
+// type are left empty; Raw holds the original line for anything not
+// modelled here.
+type Entry struct {
+	Type          string          `json:"type"`
+	UUID          string          `json:"uuid,omitempty"`
+	ParentUUID    string          `json:"parentUuid,omitempty"`
+	SessionID     string          `json:"sessionId,omitempty"`
+	Timestamp     time.Time       `json:"timestamp,omitempty"`
+	CWD           string          `json:"cwd,omitempty"`
+	GitBranch     string          `json:"gitBranch,omitempty"`
+	Version       string          `json:"version,omitempty"`
+	IsSidechain   bool            `json:"isSidechain,omitempty"`
+	IsMeta        bool            `json:"isMeta,omitempty"`
+	RequestID     string          `json:"requestId,omitempty"`
+	Message       *Message        `json:"message,omitempty"`
+	Summary       string          `json:"summary,omitempty"`
+	LeafUUID      string          `json:"leafUuid,omitempty"`
+	ToolUseResult json.RawMessage `json:"toolUseResult,omitempty"`
+	Raw           json.RawMessage `json:"-"`
+}
+
+type Message struct {
+	ID         string  `json:"id,omitempty"`
+	Role       string  `json:"role"`
+	Model      string  `json:"model,omitempty"`
+	Content    Content `json:"content"`
+	StopReason string  `json:"stop_reason,omitempty"`
+	Usage      *Usage  `json:"usage,omitempty"`
+}
+
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:              u.InputTokens + other.InputTokens,
+		OutputTokens:             u.OutputTokens + other.OutputTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens + other.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens + other.CacheReadInputTokens,
+	}
+}
+
+const (
+	BlockText       = "text"
+	BlockThinking   = "thinking"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+	BlockImage      = "image"
+)
+
+// ContentBlock is one element of a message's content. Text is set for text
+// blocks, Thinking for thinking blocks, ID/Name/Input for tool uses and
+// ToolUseID/Content/IsError for tool results.
+type ContentBlock struct {
+	Type      string                 `json:"type"`
+	Text      string                 `json:"text,omitempty"`
+	Thinking  string                 `json:"thinking,omitempty"`
+	ID        string                 `json:"id,omitempty"`
+	Name      string                 `json:"name,omitempty"`
+	Input     map[string]interface{} `json:"input,omitempty"`
+	ToolUseID string                 `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage        `json:"content,omitempty"`
+	IsError   bool                   `json:"is_error,omitempty"`
+}
+
+// ResultText returns the text of a tool result, which the transcript stores
+// either as a plain string or as a list of content blocks.
+func (b ContentBlock) ResultText() string {
+	if len(b.Content) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(b.Content, &s); err == nil {
+		return s
+	}
+	var blocks Content
+	if err := json.Unmarshal(b.Content, &blocks); err == nil {
+		return blocks.Text()
+	}
+	return string(b.Content)
+}
+
+// Content is a message body. A plain string body decodes to a single text
+// block.
+type Content []ContentBlock
+
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Content{{Type: BlockText, Text: s}}
+		return nil
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
+}
+
+// Text concatenates the text blocks, separated by newlines.
+func (c Content) Text() string {
+	var parts []string
+	for _, b := range c {
+		if b.Type == BlockText && b.Text != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (c Content) blocks(blockType string) []ContentBlock {
+	var out []ContentBlock
+	for _, b := range c {
+		if b.Type == blockType {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Text returns the message text of the entry, or the summary for summary
+// entries.
+func (e Entry) Text() string {
+	if e.Type == TypeSummary {
+		return e.Summary
+	}
+	if e.Message == nil {
+		return ""
+	}
+	return e.Message.Content.Text()
+}
+
+func (e Entry) ToolUses() []ContentBlock {
+	if e.Message == nil {
+		return nil
+	}
+	return e.Message.Content.blocks(BlockToolUse)
+}
+
+func (e Entry) ToolResults() []ContentBlock {
+	if e.Message == nil {
+		return nil
+	}
+	return e.Message.Content.blocks(BlockToolResult)
+}
+
+// IsPrompt reports whether the entry is a prompt typed by the user, as
+// opposed to a tool result or an injected meta message. Each prompt starts a
+// new turn.
+func (e Entry) IsPrompt() bool {
+	if e.Type != TypeUser || e.IsMeta || e.IsSidechain || e.Message == nil {
+		return false
+	}
+	return len(e.ToolResults()) == 0
+}
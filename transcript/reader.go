@@ -0,0 +1,77 @@
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reader streams entries from a transcript. Lines that are not valid JSON,
+// such as a final line still being written by the host, are skipped and
+// counted.
+type Reader struct {
+	r       *bufio.Reader
+	entry   Entry
+	err     error
+	line    int
+	skipped int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// Next advances to the next entry. It returns false at the end of the input
+// or on a read error, which is then available from Err.
+func (r *Reader) Next() bool {
+	for r.err == nil {
+		line, err := r.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			r.err = fmt.Errorf("failed to read transcript: %w", err)
+			return false
+		}
+		if len(line) > 0 {
+			r.line++
+			if entry, ok := decodeLine(line); ok {
+				r.entry = entry
+				return true
+			}
+			if len(bytes.TrimSpace(line)) > 0 {
+				r.skipped++
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return false
+		}
+	}
+	return false
+}
+
+func (r *Reader) Entry() Entry {
+	return r.entry
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Skipped returns the number of malformed lines skipped so far.
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+func decodeLine(line []byte) (Entry, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return Entry{}, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return Entry{}, false
+	}
+	entry.Raw = append(json.RawMessage(nil), line...)
+	return entry, true
+}
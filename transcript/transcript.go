@@ -0,0 +1,223 @@
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var ErrNoTranscript = errors.New("no transcript path")
+
+// Transcript is a lazy handle on a transcript file. Creating one does no
+// I/O; each method opens the file when called, and Entries caches the full
+// read for subsequent calls.
+type Transcript struct {
+	path string
+
+	once    sync.Once
+	entries []Entry
+	err     error
+}
+
+func New(path string) *Transcript {
+	return &Transcript{path: path}
+}
+
+func (t *Transcript) Path() string {
+	return t.path
+}
+
+// Exists reports whether the transcript file is present.
+func (t *Transcript) Exists() bool {
+	if t.path == "" {
+		return false
+	}
+	_, err := os.Stat(t.path)
+	return err == nil
+}
+
+func (t *Transcript) open() (*os.File, error) {
+	if t.path == "" {
+		return nil, ErrNoTranscript
+	}
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	return f, nil
+}
+
+// Each streams every entry to fn without holding the transcript in memory.
+// Returning an error from fn stops the iteration and returns that error.
+func (t *Transcript) Each(fn func(Entry) error) error {
+	f, err := t.open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := NewReader(f)
+	for r.Next() {
+		if err := fn(r.Entry()); err != nil {
+			return err
+		}
+	}
+	return r.Err()
+}
+
+// Entries reads and caches the whole transcript.
+func (t *Transcript) Entries() ([]Entry, error) {
+	t.once.Do(func() {
+		t.err = t.Each(func(e Entry) error {
+			t.entries = append(t.entries, e)
+			return nil
+		})
+	})
+	return t.entries, t.err
+}
+
+// Tail returns the entries of the last n turns, where a turn starts at a
+// prompt typed by the user. Only the end of the file is read, so the cost
+// depends on the size of the tail rather than of the transcript.
+func (t *Transcript) Tail(turns int) ([]Entry, error) {
+	if turns <= 0 {
+		return nil, nil
+	}
+	return t.tailFrom(func(e Entry) bool { return e.IsPrompt() }, turns)
+}
+
+// TailEntries returns the last n entries.
+func (t *Transcript) TailEntries(n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return t.tailFrom(func(Entry) bool { return true }, n)
+}
+
+func (t *Transcript) tailFrom(counts func(Entry) bool, n int) ([]Entry, error) {
+	f, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var start int64
+	seen := 0
+	err = scanBackward(f, func(line []byte, offset int64) bool {
+		entry, ok := decodeLine(line)
+		if !ok || !counts(entry) {
+			return true
+		}
+		seen++
+		if seen == n {
+			start = offset
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek transcript: %w", err)
+	}
+	var entries []Entry
+	r := NewReader(f)
+	for r.Next() {
+		entries = append(entries, r.Entry())
+	}
+	return entries, r.Err()
+}
+
+// LastAssistantMessage returns the most recent assistant entry that has
+// text, reading backwards from the end of the file.
+func (t *Transcript) LastAssistantMessage() (Entry, bool, error) {
+	f, err := t.open()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer f.Close()
+
+	var found Entry
+	ok := false
+	err = scanBackward(f, func(line []byte, _ int64) bool {
+		entry, decoded := decodeLine(line)
+		if decoded && entry.Type == TypeAssistant && entry.Text() != "" {
+			found, ok = entry, true
+			return false
+		}
+		return true
+	})
+	return found, ok, err
+}
+
+// Usage sums the token usage of all assistant messages. The host writes one
+// line per content block of a message, all carrying the same usage, so
+// usage is counted once per message ID.
+func (t *Transcript) Usage() (Usage, error) {
+	var total Usage
+	counted := make(map[string]bool)
+	err := t.Each(func(e Entry) error {
+		if e.Type != TypeAssistant || e.Message == nil || e.Message.Usage == nil {
+			return nil
+		}
+		if id := e.Message.ID; id != "" {
+			if counted[id] {
+				return nil
+			}
+			counted[id] = true
+		}
+		total = total.Add(*e.Message.Usage)
+		return nil
+	})
+	return total, err
+}
+
+const tailChunkSize = 64 * 1024
+
+// scanBackward calls visit for each non-empty line of f from last to first,
+// together with the line's byte offset, until visit returns false.
+func scanBackward(f *os.File, visit func(line []byte, offset int64) bool) error {
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat transcript: %w", err)
+	}
+
+	pos := info.Size()
+	var carry []byte
+	for pos > 0 {
+		size := int64(tailChunkSize)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+
+		chunk := make([]byte, size, int(size)+len(carry))
+		if _, err := f.ReadAt(chunk, pos); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read transcript: %w", err)
+		}
+		buf := append(chunk, carry...)
+
+		end := len(buf)
+		for i := end - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				continue
+			}
+			if line := buf[i+1 : end]; len(line) > 0 {
+				if !visit(line, pos+int64(i)+1) {
+					return nil
+				}
+			}
+			end = i
+		}
+		carry = append([]byte(nil), buf[:end]...)
+	}
+
+	if len(carry) > 0 {
+		visit(carry, 0)
+	}
+	return nil
+}
@@ -67,4 +67,25 @@ func (t ToolName) IsValid() bool {
 	default:
 		return false
 	}
-}
\ No newline at end of file
+}
+
+type PermissionDecision string
+
+const (
+	PermissionAllow PermissionDecision = "allow"
+	PermissionDeny  PermissionDecision = "deny"
+	PermissionAsk   PermissionDecision = "ask"
+)
+
+func (d PermissionDecision) String() string {
+	return string(d)
+}
+
+func (d PermissionDecision) IsValid() bool {
+	switch d {
+	case PermissionAllow, PermissionDeny, PermissionAsk:
+		return true
+	default:
+		return false
+	}
+}
@@ -1,7 +1,12 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/logging"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/transcript"
 )
 
 type BaseInput struct {
@@ -9,12 +14,16 @@ type BaseInput struct {
 	TranscriptPath string `json:"transcript_path"`
 	CWD            string `json:"cwd"`
 	HookEventName  string `json:"hook_event_name"`
+
+	ctx        context.Context
+	transcript *transcript.Transcript
 }
 
 type PreToolUseInput struct {
 	BaseInput
 	ToolName  ToolName               `json:"tool_name"`
 	ToolInput map[string]interface{} `json:"tool_input"`
+	ToolUseID string                 `json:"tool_use_id,omitempty"`
 }
 
 type PostToolUseInput struct {
@@ -22,6 +31,7 @@ type PostToolUseInput struct {
 	ToolName     ToolName               `json:"tool_name"`
 	ToolInput    map[string]interface{} `json:"tool_input"`
 	ToolResponse interface{}            `json:"tool_response"`
+	ToolUseID    string                 `json:"tool_use_id,omitempty"`
 }
 
 type NotificationInput struct {
@@ -55,11 +65,17 @@ type SessionStartInput struct {
 	Source SessionSource `json:"source"`
 }
 
+// HookInput is implemented by every event input. Types implementing it
+// outside this package should embed BaseInput, which provides all of its
+// methods, including any added later.
 type HookInput interface {
 	GetSessionID() string
 	GetTranscriptPath() string
 	GetCWD() string
 	GetEventName() string
+	Transcript() *transcript.Transcript
+	Context() context.Context
+	Logger() *slog.Logger
 }
 
 func (b BaseInput) GetSessionID() string {
@@ -78,6 +94,66 @@ func (b BaseInput) GetEventName() string {
 	return b.HookEventName
 }
 
+// Transcript returns a lazy handle on the session transcript. The file is
+// not read until one of the handle's methods is called. Inputs returned by
+// ParseInput share one handle across calls, so Entries reads the file once.
+func (b BaseInput) Transcript() *transcript.Transcript {
+	if b.transcript == nil || b.transcript.Path() != b.TranscriptPath {
+		return transcript.New(b.TranscriptPath)
+	}
+	return b.transcript
+}
+
+// Context returns the context the router attached to this invocation, which
+// carries its deadline and per-invocation values such as the session state.
+// It is never nil.
+func (b BaseInput) Context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+// Logger returns the invocation's logger, pre-populated with the session ID,
+// event name, tool name and invocation ID.
+func (b BaseInput) Logger() *slog.Logger {
+	return logging.FromContext(b.Context())
+}
+
+// WithContext returns a copy of input carrying ctx.
+func WithContext(input HookInput, ctx context.Context) HookInput {
+	switch in := input.(type) {
+	case PreToolUseInput:
+		in.ctx = ctx
+		return in
+	case PostToolUseInput:
+		in.ctx = ctx
+		return in
+	case NotificationInput:
+		in.ctx = ctx
+		return in
+	case UserPromptSubmitInput:
+		in.ctx = ctx
+		return in
+	case StopInput:
+		in.ctx = ctx
+		return in
+	case SubagentStopInput:
+		in.ctx = ctx
+		return in
+	case PreCompactInput:
+		in.ctx = ctx
+		return in
+	case SessionStartInput:
+		in.ctx = ctx
+		return in
+	case BaseInput:
+		in.ctx = ctx
+		return in
+	}
+	return input
+}
+
 func ParseInput(data []byte) (HookInput, EventName, error) {
 	var base BaseInput
 	if err := json.Unmarshal(data, &base); err != nil {
@@ -93,34 +169,42 @@ func ParseInput(data []byte) (HookInput, EventName, error) {
 	case EventPreToolUse:
 		var input PreToolUseInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventPostToolUse:
 		var input PostToolUseInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventNotification:
 		var input NotificationInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventUserPromptSubmit:
 		var input UserPromptSubmitInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventStop:
 		var input StopInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventSubagentStop:
 		var input SubagentStopInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventPreCompact:
 		var input PreCompactInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	case EventSessionStart:
 		var input SessionStartInput
 		err := json.Unmarshal(data, &input)
+		input.transcript = transcript.New(input.TranscriptPath)
 		return input, eventName, err
 	default:
 		return nil, eventName, &InvalidEventError{EventName: base.HookEventName}
@@ -133,4 +217,4 @@ type InvalidEventError struct {
 
 func (e *InvalidEventError) Error() string {
 	return "invalid event name: " + e.EventName
-}
\ No newline at end of file
+}
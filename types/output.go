@@ -1,9 +1,12 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
 const (
@@ -11,6 +14,11 @@ const (
 	ExitBlocking = 2
 )
 
+// DecisionBlock is the decision of PostToolUse outputs that report back to
+// the model, of UserPromptSubmit outputs that reject the prompt and of Stop
+// and SubagentStop outputs that keep it working.
+const DecisionBlock = "block"
+
 type BaseOutput struct {
 	Continue   *bool   `json:"continue,omitempty"`
 	StopReason *string `json:"stopReason,omitempty"`
@@ -18,8 +26,16 @@ type BaseOutput struct {
 
 type PreToolUseOutput struct {
 	BaseOutput
-	AllowTool     *bool                  `json:"allowTool,omitempty"`
-	ModifiedInput map[string]interface{} `json:"modifiedInput,omitempty"`
+	AllowTool          *bool                     `json:"allowTool,omitempty"`
+	ModifiedInput      map[string]interface{}    `json:"modifiedInput,omitempty"`
+	HookSpecificOutput *PreToolUseSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+// PreToolUseSpecificOutput carries the permission decision for a tool call.
+type PreToolUseSpecificOutput struct {
+	HookEventName            EventName          `json:"hookEventName"`
+	PermissionDecision       PermissionDecision `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string             `json:"permissionDecisionReason,omitempty"`
 }
 
 type PostToolUseOutput struct {
@@ -27,6 +43,10 @@ type PostToolUseOutput struct {
 	ProcessResult *bool       `json:"processResult,omitempty"`
 	Message       *string     `json:"message,omitempty"`
 	Data          interface{} `json:"data,omitempty"`
+	// Decision "block" feeds Reason back to the model, for example to
+	// report problems in a file it just wrote. The tool has already run.
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
 }
 
 type NotificationOutput struct {
@@ -38,20 +58,38 @@ type NotificationOutput struct {
 
 type UserPromptSubmitOutput struct {
 	BaseOutput
-	AllowSubmit    *bool   `json:"allowSubmit,omitempty"`
-	ModifiedPrompt *string `json:"modifiedPrompt,omitempty"`
+	AllowSubmit        *bool                           `json:"allowSubmit,omitempty"`
+	ModifiedPrompt     *string                         `json:"modifiedPrompt,omitempty"`
+	HookSpecificOutput *UserPromptSubmitSpecificOutput `json:"hookSpecificOutput,omitempty"`
+	// Decision "block" rejects the prompt, showing Reason to the user.
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// UserPromptSubmitSpecificOutput carries context added to the prompt.
+type UserPromptSubmitSpecificOutput struct {
+	HookEventName     EventName `json:"hookEventName"`
+	AdditionalContext string    `json:"additionalContext,omitempty"`
 }
 
 type StopOutput struct {
 	BaseOutput
 	AllowStop *bool   `json:"allowStop,omitempty"`
 	Message   *string `json:"message,omitempty"`
+	// Decision "block" keeps the model working instead of stopping, with
+	// Reason telling it what is left to do.
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
 }
 
 type SubagentStopOutput struct {
 	BaseOutput
 	AllowStop *bool   `json:"allowStop,omitempty"`
 	Message   *string `json:"message,omitempty"`
+	// Decision "block" keeps the model working instead of stopping, with
+	// Reason telling it what is left to do.
+	Decision string `json:"decision,omitempty"`
+	Reason   string `json:"reason,omitempty"`
 }
 
 type PreCompactOutput struct {
@@ -62,8 +100,15 @@ type PreCompactOutput struct {
 
 type SessionStartOutput struct {
 	BaseOutput
-	Message *string     `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
+	Message            *string                     `json:"message,omitempty"`
+	Data               interface{}                 `json:"data,omitempty"`
+	HookSpecificOutput *SessionStartSpecificOutput `json:"hookSpecificOutput,omitempty"`
+}
+
+// SessionStartSpecificOutput carries context added to the new session.
+type SessionStartSpecificOutput struct {
+	HookEventName     EventName `json:"hookEventName"`
+	AdditionalContext string    `json:"additionalContext,omitempty"`
 }
 
 type HookOutput interface {
@@ -71,6 +116,14 @@ type HookOutput interface {
 	ExitWith() int
 }
 
+// GetStopReason returns the stop reason, or "" if none is set.
+func (o BaseOutput) GetStopReason() string {
+	if o.StopReason == nil {
+		return ""
+	}
+	return *o.StopReason
+}
+
 func (o BaseOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -93,9 +146,46 @@ func (o PreToolUseOutput) ExitWith() int {
 	if o.AllowTool != nil && !*o.AllowTool {
 		return ExitBlocking
 	}
+	if o.Decision() == PermissionDeny {
+		return ExitBlocking
+	}
 	return ExitSuccess
 }
 
+// Decision returns the permission decision carried by the output, falling
+// back to AllowTool when no explicit decision was set. It returns an empty
+// decision when the output expresses no opinion.
+func (o PreToolUseOutput) Decision() PermissionDecision {
+	if o.HookSpecificOutput != nil && o.HookSpecificOutput.PermissionDecision != "" {
+		return o.HookSpecificOutput.PermissionDecision
+	}
+	if o.AllowTool != nil {
+		if *o.AllowTool {
+			return PermissionAllow
+		}
+		return PermissionDeny
+	}
+	return ""
+}
+
+// Reason returns the permission decision reason, if any.
+func (o PreToolUseOutput) Reason() string {
+	if o.HookSpecificOutput != nil {
+		return o.HookSpecificOutput.PermissionDecisionReason
+	}
+	return ""
+}
+
+// Blocked reports whether the output feeds its reason back to the model.
+func (o PostToolUseOutput) Blocked() bool {
+	return o.Decision == DecisionBlock
+}
+
+// GetReason returns the reason given with the decision.
+func (o PostToolUseOutput) GetReason() string {
+	return o.Reason
+}
+
 func (o PostToolUseOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -118,6 +208,33 @@ func (o NotificationOutput) ExitWith() int {
 	return ExitSuccess
 }
 
+// Blocked reports whether the output rejects the prompt.
+func (o UserPromptSubmitOutput) Blocked() bool {
+	return o.Decision == DecisionBlock
+}
+
+// GetReason returns the reason given with the decision.
+func (o UserPromptSubmitOutput) GetReason() string {
+	return o.Reason
+}
+
+// GetAdditionalContext returns the context added to the prompt, if any.
+func (o UserPromptSubmitOutput) GetAdditionalContext() string {
+	if o.HookSpecificOutput == nil {
+		return ""
+	}
+	return o.HookSpecificOutput.AdditionalContext
+}
+
+// WithAdditionalContext returns a copy of o adding text to the prompt.
+func (o UserPromptSubmitOutput) WithAdditionalContext(text string) UserPromptSubmitOutput {
+	o.HookSpecificOutput = &UserPromptSubmitSpecificOutput{
+		HookEventName:     EventUserPromptSubmit,
+		AdditionalContext: text,
+	}
+	return o
+}
+
 func (o UserPromptSubmitOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -132,6 +249,16 @@ func (o UserPromptSubmitOutput) ExitWith() int {
 	return ExitSuccess
 }
 
+// Blocked reports whether the output keeps the model working.
+func (o StopOutput) Blocked() bool {
+	return o.Decision == DecisionBlock
+}
+
+// GetReason returns the reason given with the decision.
+func (o StopOutput) GetReason() string {
+	return o.Reason
+}
+
 func (o StopOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -146,6 +273,16 @@ func (o StopOutput) ExitWith() int {
 	return ExitSuccess
 }
 
+// Blocked reports whether the output keeps the model working.
+func (o SubagentStopOutput) Blocked() bool {
+	return o.Decision == DecisionBlock
+}
+
+// GetReason returns the reason given with the decision.
+func (o SubagentStopOutput) GetReason() string {
+	return o.Reason
+}
+
 func (o SubagentStopOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -174,6 +311,23 @@ func (o PreCompactOutput) ExitWith() int {
 	return ExitSuccess
 }
 
+// GetAdditionalContext returns the context added to the session, if any.
+func (o SessionStartOutput) GetAdditionalContext() string {
+	if o.HookSpecificOutput == nil {
+		return ""
+	}
+	return o.HookSpecificOutput.AdditionalContext
+}
+
+// WithAdditionalContext returns a copy of o adding text to the session.
+func (o SessionStartOutput) WithAdditionalContext(text string) SessionStartOutput {
+	o.HookSpecificOutput = &SessionStartSpecificOutput{
+		HookEventName:     EventSessionStart,
+		AdditionalContext: text,
+	}
+	return o
+}
+
 func (o SessionStartOutput) ToJSON() ([]byte, error) {
 	return json.Marshal(o)
 }
@@ -186,13 +340,87 @@ func (o SessionStartOutput) ExitWith() int {
 }
 
 func OutputAndExit(output HookOutput) {
-	if jsonData, err := output.ToJSON(); err != nil {
+	if err := WriteOutput(os.Stdout, output); err != nil {
 		fmt.Fprintf(os.Stderr, "Error marshaling output: %v\n", err)
-		os.Exit(1)
-	} else {
-		fmt.Print(string(jsonData))
+		Exit(1)
+	}
+	WriteFeedback(os.Stderr, output)
+	Exit(output.ExitWith())
+}
+
+// WriteOutput writes the JSON encoding of output to w.
+func WriteOutput(w io.Writer, output HookOutput) error {
+	jsonData, err := output.ToJSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(jsonData)
+	return err
+}
+
+// WriteFeedback writes the reason of an output that exits with
+// ExitBlocking to w, which should be stderr: on that exit code the host
+// ignores stdout and shows stderr instead. It writes nothing for other
+// outputs or when there is no reason.
+func WriteFeedback(w io.Writer, output HookOutput) error {
+	if output.ExitWith() != ExitBlocking {
+		return nil
+	}
+	reason := BlockingReason(output)
+	if reason == "" {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, reason)
+	return err
+}
+
+// BlockingReason returns the reason an output gives for its decision: the
+// permission decision reason of a PreToolUse output, the reason of a block
+// decision, or the stop reason.
+func BlockingReason(output HookOutput) string {
+	if o, ok := output.(interface{ Reason() string }); ok && o.Reason() != "" {
+		return o.Reason()
+	}
+	if o, ok := output.(interface{ GetReason() string }); ok && o.GetReason() != "" {
+		return o.GetReason()
+	}
+	if o, ok := output.(interface{ GetStopReason() string }); ok {
+		return o.GetStopReason()
+	}
+	return ""
+}
+
+var (
+	exitMu    sync.Mutex
+	exitHooks []func()
+)
+
+// OnExit registers fn to run before Exit terminates the process, for
+// example to flush buffered logs. Hooks run in reverse order of
+// registration.
+func OnExit(fn func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// Exit runs the hooks registered with OnExit and exits with code.
+func Exit(code int) {
+	RunExitHooks()
+	os.Exit(code)
+}
+
+// RunExitHooks runs the hooks registered with OnExit without exiting.
+// Long-running processes, such as a hook daemon, call it after each
+// request so that buffered records are written promptly.
+func RunExitHooks() {
+	exitMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
 	}
-	os.Exit(output.ExitWith())
 }
 
 func Success() HookOutput {
@@ -205,4 +433,83 @@ func Block(reason string) HookOutput {
 		Continue:   &continueVal,
 		StopReason: &reason,
 	}
-}
\ No newline at end of file
+}
+
+// Permission builds a PreToolUse output carrying the given permission
+// decision and reason. A deny exits with ExitBlocking, so its reason also
+// goes to stderr through WriteFeedback.
+func Permission(decision PermissionDecision, reason string) PreToolUseOutput {
+	output := PreToolUseOutput{
+		HookSpecificOutput: &PreToolUseSpecificOutput{
+			HookEventName:            EventPreToolUse,
+			PermissionDecision:       decision,
+			PermissionDecisionReason: reason,
+		},
+	}
+	switch decision {
+	case PermissionAllow:
+		allowTool := true
+		output.AllowTool = &allowTool
+	case PermissionDeny:
+		allowTool := false
+		output.AllowTool = &allowTool
+	}
+	return output
+}
+
+// KeepWorking returns a Stop output that prevents the model from stopping
+// and hands it reason as its next instruction. Unlike AllowStop false, the
+// output exits 0 so that the host reads the reason from it.
+func KeepWorking(reason string) StopOutput {
+	return StopOutput{Decision: DecisionBlock, Reason: reason}
+}
+
+func Allow(reason string) PreToolUseOutput {
+	return Permission(PermissionAllow, reason)
+}
+
+func Deny(reason string) PreToolUseOutput {
+	return Permission(PermissionDeny, reason)
+}
+
+func Ask(reason string) PreToolUseOutput {
+	return Permission(PermissionAsk, reason)
+}
+
+// ParseOutput decodes a hook's stdout as the output type of eventName.
+// Fields the type does not define are an error, so the result tells whether
+// the output conforms to the SDK's output types.
+func ParseOutput(eventName EventName, data []byte) (HookOutput, error) {
+	switch eventName {
+	case EventPreToolUse:
+		return decodeOutput[PreToolUseOutput](data)
+	case EventPostToolUse:
+		return decodeOutput[PostToolUseOutput](data)
+	case EventNotification:
+		return decodeOutput[NotificationOutput](data)
+	case EventUserPromptSubmit:
+		return decodeOutput[UserPromptSubmitOutput](data)
+	case EventStop:
+		return decodeOutput[StopOutput](data)
+	case EventSubagentStop:
+		return decodeOutput[SubagentStopOutput](data)
+	case EventPreCompact:
+		return decodeOutput[PreCompactOutput](data)
+	case EventSessionStart:
+		return decodeOutput[SessionStartOutput](data)
+	}
+	return nil, &InvalidEventError{EventName: string(eventName)}
+}
+
+func decodeOutput[T HookOutput](data []byte) (HookOutput, error) {
+	var output T
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&output); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON object")
+	}
+	return output, nil
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteFeedback(t *testing.T) {
+	allowSubmit := false
+	tests := []struct {
+		name     string
+		output   HookOutput
+		exitCode int
+		stderr   string
+	}{
+		{"deny", Deny("rm is not allowed"), ExitBlocking, "rm is not allowed\n"},
+		{"ask", Ask("check this"), ExitSuccess, ""},
+		{"allow", Allow("fine"), ExitSuccess, ""},
+		{"block", Block("stopped"), ExitBlocking, "stopped\n"},
+		{"keep working", KeepWorking("tests fail"), ExitSuccess, ""},
+		{"prompt rejected", UserPromptSubmitOutput{AllowSubmit: &allowSubmit}, ExitBlocking, ""},
+		{"success", Success(), ExitSuccess, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.output.ExitWith(); got != tt.exitCode {
+			t.Errorf("%s: exit code %d, want %d", tt.name, got, tt.exitCode)
+		}
+		var stderr bytes.Buffer
+		if err := WriteFeedback(&stderr, tt.output); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if stderr.String() != tt.stderr {
+			t.Errorf("%s: stderr %q, want %q", tt.name, stderr.String(), tt.stderr)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCheckTimeout bounds a check that sets no timeout of its own.
+const DefaultCheckTimeout = 2 * time.Minute
+
+// Check is one verification command, run with sh -c in the session's
+// working directory, or in Dir relative to it. A check passes when the
+// command exits 0.
+type Check struct {
+	Name    string        `json:"name" yaml:"name"`
+	Command string        `json:"command" yaml:"command"`
+	Dir     string        `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+// Command returns a check named after its command.
+func Command(command string) Check {
+	return Check{Name: command, Command: command}
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Check    Check
+	ExitCode int
+	Output   string
+	Duration time.Duration
+	// Err is set when the command could not run or timed out.
+	Err error
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil && r.ExitCode == 0
+}
+
+// Run runs the check in cwd and waits for it.
+func (c Check) Run(ctx context.Context, cwd string) Result {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+	cmd.Dir = cwd
+	if c.Dir != "" {
+		cmd.Dir = filepath.Join(cwd, c.Dir)
+	}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	result := Result{Check: c, Output: output.String(), Duration: time.Since(start)}
+	var exit *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		result.Err = fmt.Errorf("timed out after %s", timeout)
+	case errors.As(err, &exit):
+		result.ExitCode = exit.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Err = err
+	}
+	return result
+}
+
+// Summary describes a failed check in a few lines: the command, how it
+// failed and the last maxLines lines of its output.
+func (r Result) Summary(maxLines int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s failed", r.Check.Name)
+	if r.Check.Name != r.Check.Command {
+		fmt.Fprintf(&b, " (%s)", r.Check.Command)
+	}
+	if r.Err != nil {
+		fmt.Fprintf(&b, ": %v", r.Err)
+	} else {
+		fmt.Fprintf(&b, " with exit code %d", r.ExitCode)
+	}
+	lines := strings.Split(strings.TrimRight(r.Output, "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return b.String()
+	}
+	if len(lines) > maxLines {
+		fmt.Fprintf(&b, "\n... %d earlier lines omitted", len(lines)-maxLines)
+		lines = lines[len(lines)-maxLines:]
+	}
+	for _, line := range lines {
+		b.WriteString("\n    ")
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package verify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/inject"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+// Failures are the summaries of the checks that failed the last time a
+// Controller ran them in a session. They are cleared once the checks pass.
+type Failures struct {
+	At        time.Time `json:"at"`
+	Summaries []string  `json:"summaries"`
+}
+
+// LastFailures returns the failures recorded in the session of input.
+func LastFailures(input types.HookInput) (Failures, bool, error) {
+	return state.Get[Failures](input.Context(), failuresKey)
+}
+
+// RecentFailures provides the failures recorded in the session as
+// context, so the model sees them on its next prompt.
+func RecentFailures() inject.Provider {
+	return inject.ProviderFunc(func(input types.HookInput) ([]inject.Snippet, error) {
+		failures, found, err := LastFailures(input)
+		if errors.Is(err, state.ErrNoSession) || !found {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []inject.Snippet{{
+			Title:    fmt.Sprintf("Verification failures (%s)", failures.At.Local().Format(time.Kitchen)),
+			Text:     strings.Join(failures.Summaries, "\n\n"),
+			Priority: inject.PriorityHigh,
+		}}, nil
+	})
+}
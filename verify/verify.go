@@ -0,0 +1,158 @@
+// Package verify keeps the model working until verification commands, such
+// as tests, vet or a linter, pass. Its Controller handles Stop and
+// SubagentStop: when a check fails it blocks the stop and hands the model a
+// short summary of the failures as its next instruction.
+//
+// To avoid looping forever, the controller counts the stops it blocked in a
+// row in the session state. The count starts over when stop_hook_active is
+// false, that is when the model stops on its own rather than after a
+// blocked stop, and the controller lets the model stop once the count
+// reaches the maximum.
+package verify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/state"
+	"github.com/HeroSizy/claude-code-hooks-go-sdk/types"
+)
+
+const (
+	// DefaultMaxIterations is how many stops in a row a Controller blocks.
+	DefaultMaxIterations = 3
+	// DefaultOutputLines is how many trailing output lines of a failed
+	// check the summary shows.
+	DefaultOutputLines = 20
+
+	keyPrefix   = "verify/iterations/"
+	failuresKey = "verify/failures"
+)
+
+// Controller runs checks when the model stops. Checks run one after the
+// other in the order given, all of them, so the summary covers every
+// failure. Give the router a timeout long enough for the checks:
+//
+//	router := handler.NewRouter().
+//	    On(types.EventStop, verify.New(
+//	        verify.Command("go vet ./..."),
+//	        verify.Command("go test ./..."),
+//	    )).
+//	    WithTimeout(5 * time.Minute)
+type Controller struct {
+	checks        []Check
+	maxIterations int
+	outputLines   int
+}
+
+func New(checks ...Check) *Controller {
+	return &Controller{
+		checks:        checks,
+		maxIterations: DefaultMaxIterations,
+		outputLines:   DefaultOutputLines,
+	}
+}
+
+// WithMaxIterations sets how many stops in a row may be blocked. Zero
+// never blocks a stop that follows a blocked one, honoring
+// stop_hook_active alone.
+func (c *Controller) WithMaxIterations(n int) *Controller {
+	c.maxIterations = n
+	return c
+}
+
+func (c *Controller) WithOutputLines(n int) *Controller {
+	c.outputLines = n
+	return c
+}
+
+func (c *Controller) HandleEvent(input types.HookInput, eventName types.EventName) (types.HookOutput, error) {
+	switch in := input.(type) {
+	case types.StopInput:
+		return c.HandleStop(in)
+	case types.SubagentStopInput:
+		return c.HandleSubagentStop(in)
+	}
+	return types.Success(), nil
+}
+
+func (c *Controller) HandleStop(input types.StopInput) (types.StopOutput, error) {
+	if reason, block := c.verify(input, input.StopHookActive); block {
+		return types.KeepWorking(reason), nil
+	}
+	return types.StopOutput{}, nil
+}
+
+func (c *Controller) HandleSubagentStop(input types.SubagentStopInput) (types.SubagentStopOutput, error) {
+	if reason, block := c.verify(input, input.StopHookActive); block {
+		return types.SubagentStopOutput{Decision: types.DecisionBlock, Reason: reason}, nil
+	}
+	return types.SubagentStopOutput{}, nil
+}
+
+// verify runs the checks unless the stop has been blocked too often
+// already, and returns the reason to block with if any failed.
+func (c *Controller) verify(input types.HookInput, active bool) (string, bool) {
+	logger := input.Logger()
+	key := keyPrefix + input.GetEventName()
+	iterations, err := c.iterations(input, key, active)
+	if err != nil {
+		// Without a counter, stop_hook_active is the only guard.
+		logger.Warn("verification counter unavailable", "error", err)
+		if active {
+			return "", false
+		}
+	}
+	if active && iterations >= c.maxIterations {
+		logger.Warn("verification still failing, letting the model stop", "iterations", iterations)
+		c.reset(input, key)
+		return "", false
+	}
+
+	var failures []string
+	for _, check := range c.checks {
+		result := check.Run(input.Context(), input.GetCWD())
+		logger.Debug("verification check finished",
+			"check", check.Name, "exit_code", result.ExitCode, "duration", result.Duration)
+		if !result.Passed() {
+			failures = append(failures, result.Summary(c.outputLines))
+		}
+	}
+	if len(failures) == 0 {
+		c.reset(input, key, failuresKey)
+		return "", false
+	}
+
+	iterations++
+	if err := state.Update(input.Context(), func(tx *state.Tx) error {
+		if err := tx.Set(failuresKey, Failures{At: time.Now(), Summaries: failures}); err != nil {
+			return err
+		}
+		return tx.Set(key, iterations)
+	}); err != nil {
+		logger.Warn("verification counter not saved", "error", err)
+	}
+	reason := fmt.Sprintf("Verification failed (attempt %d of %d). Fix the following before finishing:\n\n%s",
+		iterations, c.maxIterations, strings.Join(failures, "\n\n"))
+	return reason, true
+}
+
+// iterations returns the number of stops blocked in a row, starting over
+// when the model stopped on its own.
+func (c *Controller) iterations(input types.HookInput, key string, active bool) (int, error) {
+	if !active {
+		return 0, c.reset(input, key)
+	}
+	n, _, err := state.Get[int](input.Context(), key)
+	return n, err
+}
+
+func (c *Controller) reset(input types.HookInput, keys ...string) error {
+	return state.Update(input.Context(), func(tx *state.Tx) error {
+		for _, key := range keys {
+			tx.Delete(key)
+		}
+		return nil
+	})
+}